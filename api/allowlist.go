@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/mattermost/pillar/utils"
+)
+
+// IPAllowlist rejects requests from clients outside of a set of CIDR blocks.
+type IPAllowlist struct {
+	allowedCIDRs   []string
+	trustedProxies []string
+	logger         logrus.FieldLogger
+}
+
+// NewIPAllowlist creates an allowlist admitting clients within the allowed
+// CIDR blocks. The client address is taken from the forwarding headers only
+// when the request arrives through one of the trusted proxy CIDR blocks.
+func NewIPAllowlist(allowedCIDRs, trustedProxies []string, logger logrus.FieldLogger) (*IPAllowlist, error) {
+	if len(allowedCIDRs) == 0 {
+		return nil, errors.New("at least one allowed cidr block is required")
+	}
+
+	err := utils.ValidateCIDRBlocks(allowedCIDRs)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid allowed cidr blocks")
+	}
+	err = utils.ValidateCIDRBlocks(trustedProxies)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid trusted proxies")
+	}
+
+	return &IPAllowlist{
+		allowedCIDRs:   allowedCIDRs,
+		trustedProxies: trustedProxies,
+		logger:         logger,
+	}, nil
+}
+
+// Middleware wraps the given handler, responding with 403 Forbidden to
+// requests from clients outside of the allowlist.
+func (a *IPAllowlist) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ipAddress, err := utils.GetIPFromRequest(r, a.trustedProxies)
+		if err != nil {
+			a.reject(w, r, errors.Wrap(err, "failed to determine client ip address"))
+			return
+		}
+
+		allowed, err := utils.IPAddressInCIDRBlock(ipAddress, a.allowedCIDRs)
+		if err != nil {
+			a.reject(w, r, err)
+			return
+		}
+		if !allowed {
+			a.reject(w, r, errors.Errorf("ip address %s is not allowed", ipAddress))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *IPAllowlist) reject(w http.ResponseWriter, r *http.Request, err error) {
+	a.logger.WithError(err).WithFields(logrus.Fields{
+		"path":        r.URL.Path,
+		"remote_addr": r.RemoteAddr,
+	}).Warn("Rejected request from outside of the ip allowlist")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	b, _ := json.Marshal(&Error{Message: "access denied"})
+	w.Write(b)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestNewIPAllowlist(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	_, err := NewIPAllowlist(nil, nil, logger)
+	assert.Error(t, err)
+
+	_, err = NewIPAllowlist([]string{"10.0.0.0/8", "invalid"}, nil, logger)
+	assert.Error(t, err)
+
+	_, err = NewIPAllowlist([]string{"10.0.0.0/8"}, []string{"invalid"}, logger)
+	assert.Error(t, err)
+
+	_, err = NewIPAllowlist([]string{"10.0.0.0/8", "fd00::/8"}, []string{"192.168.0.0/16"}, logger)
+	assert.NoError(t, err)
+}
+
+func TestIPAllowlistMiddleware(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	allowlist, err := NewIPAllowlist([]string{"10.0.0.0/8", "fd00::/8"}, []string{"192.168.0.0/16"}, logger)
+	require.NoError(t, err)
+
+	handler := allowlist.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		expectedCode int
+	}{
+		{"allowed client", "10.1.2.3:4567", "", "", http.StatusOK},
+		{"allowed ipv6 client", "[fd00::1]:4567", "", "", http.StatusOK},
+		{"denied client", "203.0.113.1:4567", "", "", http.StatusForbidden},
+		{"spoofed forwarded for from untrusted client", "203.0.113.1:4567", "10.1.2.3", "", http.StatusForbidden},
+		{"spoofed real ip from untrusted client", "203.0.113.1:4567", "", "10.1.2.3", http.StatusForbidden},
+		{"forwarded for through trusted proxy", "192.168.1.1:4567", "10.1.2.3", "", http.StatusOK},
+		{"denied forwarded for through trusted proxy", "192.168.1.1:4567", "203.0.113.1", "", http.StatusForbidden},
+		{"spoofed forwarded for through trusted proxy", "192.168.1.1:4567", "10.1.2.3, 203.0.113.1", "", http.StatusForbidden},
+		{"forwarded for through chained trusted proxies", "192.168.1.1:4567", "10.1.2.3, 192.168.2.2", "", http.StatusOK},
+		{"real ip through trusted proxy", "192.168.1.1:4567", "", "10.1.2.3", http.StatusOK},
+		{"trusted proxy without forwarding headers", "192.168.1.1:4567", "", "", http.StatusForbidden},
+		{"invalid forwarded for through trusted proxy", "192.168.1.1:4567", "invalid", "", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/workspaces/list", nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+			if tc.realIP != "" {
+				r.Header.Set("X-Real-Ip", tc.realIP)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusForbidden {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{"Message":"access denied"}`, w.Body.String())
+			}
+		})
+	}
+}
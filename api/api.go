@@ -16,5 +16,9 @@ func initializeRoutes(rootRouter *mux.Router, context *Context) {
 	apiRouter := rootRouter.PathPrefix(pathPrefix).Subrouter()
 
 	initWorkspace(apiRouter, context)
+	initGroup(apiRouter, context)
+	initCluster(apiRouter, context)
+	initPlugin(apiRouter, context)
+	initAudit(apiRouter, context)
 	initStatic(rootRouter, context)
 }
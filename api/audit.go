@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/pillar/utils"
+)
+
+// initAudit registers audit endpoints on the given router.
+func initAudit(apiRouter *mux.Router, context *Context) {
+	apiRouter.Handle("/audit", newAPIHandler(context, handleGetAuditRecords, PermissionAuditRead)).Methods("GET")
+}
+
+// AuditRecord is a single API request recorded in the audit log.
+type AuditRecord struct {
+	RequestID     string `json:"request_id"`
+	CreateAt      int64  `json:"create_at"`
+	Actor         string `json:"actor"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	WorkspaceID   string `json:"workspace_id"`
+	Permission    string `json:"permission"`
+	IPAddress     string `json:"ip_address"`
+	StatusCode    int    `json:"status_code"`
+	Justification string `json:"justification"`
+	TicketID      string `json:"ticket_id"`
+}
+
+// AuditFilter describes the parameters used to constrain a set of audit records.
+type AuditFilter struct {
+	Actor       string
+	WorkspaceID string
+	// Since and Until bound the creation time of the records, in milliseconds since the epoch.
+	Since   int64
+	Until   int64
+	Page    int
+	PerPage int
+}
+
+// AuditStore persists audit records.
+type AuditStore interface {
+	CreateAuditRecord(record *AuditRecord) error
+	GetAuditRecords(filter *AuditFilter) ([]*AuditRecord, error)
+}
+
+// recordAudit persists an audit record of the given request, if an audit store is configured.
+func recordAudit(c *Context, r *http.Request, permission Permission, statusCode int) {
+	if c.AuditStore == nil {
+		return
+	}
+
+	record := &AuditRecord{
+		RequestID:   c.RequestID,
+		Actor:       c.Identity.Name(),
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		WorkspaceID: mux.Vars(r)["workspace"],
+		Permission:  string(permission),
+		StatusCode:  statusCode,
+	}
+	if c.Justification != nil {
+		record.Justification = c.Justification.Reason
+		record.TicketID = c.Justification.TicketID
+	}
+
+	ipAddress, err := utils.GetIPFromRequest(r, c.TrustedProxies)
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to determine client IP address for audit record")
+	} else {
+		record.IPAddress = ipAddress.String()
+	}
+
+	err = c.AuditStore.CreateAuditRecord(record)
+	if err != nil {
+		c.Logger.WithError(err).Error("Failed to create audit record")
+	}
+}
+
+// handleGetAuditRecords responds to GET /api/v1/audit, listing audit records that match the filters.
+func handleGetAuditRecords(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.AuditStore == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		c.writeAndLogError(w, errors.New("audit log is not configured"))
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	records, err := c.AuditStore.GetAuditRecords(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(records)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func parseAuditFilter(r *http.Request) (*AuditFilter, error) {
+	query := r.URL.Query()
+
+	page, perPage, err := parsePaging(query)
+	if err != nil {
+		return nil, err
+	}
+	since, err := parseInt64(query, "since", 0)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseInt64(query, "until", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditFilter{
+		Actor:       query.Get("actor"),
+		WorkspaceID: query.Get("workspace"),
+		Since:       since,
+		Until:       until,
+		Page:        page,
+		PerPage:     perPage,
+	}, nil
+}
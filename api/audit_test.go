@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+	"github.com/mattermost/pillar/utils"
+)
+
+// memoryAuditStore is an in-memory AuditStore used to observe recorded requests.
+type memoryAuditStore struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+}
+
+func (s *memoryAuditStore) CreateAuditRecord(record *AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryAuditStore) GetAuditRecords(filter *AuditFilter) ([]*AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := []*AuditRecord{}
+	for _, record := range s.records {
+		if filter.Actor != "" && record.Actor != filter.Actor {
+			continue
+		}
+		if filter.WorkspaceID != "" && record.WorkspaceID != filter.WorkspaceID {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (s *memoryAuditStore) last() *AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.records) == 0 {
+		return nil
+	}
+	return s.records[len(s.records)-1]
+}
+
+func TestAuditLog(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+	auditStore := &memoryAuditStore{}
+
+	authorizer, err := NewAuthorizer(&RBACConfig{
+		DefaultRoles: []string{RoleViewer},
+		Bindings:     []RoleBinding{{Role: RoleAdmin, Subjects: []string{"admin"}}},
+	})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:        logger,
+		CloudClient:   mockCloudClient,
+		Authenticator: NewTokenAuthenticator(map[string]string{"viewer": "viewertoken", "admin": "admintoken"}),
+		Authorizer:    authorizer,
+		AuditStore:    auditStore,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	viewerClient := NewClientWithToken(ts.URL, "viewertoken")
+	adminClient := NewClientWithToken(ts.URL, "admintoken")
+	viewerClient.SetJustification("customer requested help", "")
+	adminClient.SetJustification("customer requested help", "")
+
+	t.Run("successful request", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+		mockCloudClient.EXPECT().UpdateInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+
+		_, err := adminClient.UpdateWorkspace("installationid", &UpdateWorkspaceRequest{Version: utils.NewString("5.31.0")})
+		require.NoError(t, err)
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.NotEmpty(t, record.RequestID)
+		assert.Equal(t, "admin", record.Actor)
+		assert.Equal(t, "PUT", record.Method)
+		assert.Equal(t, "/api/v1/workspaces/installationid", record.Path)
+		assert.Equal(t, "installationid", record.WorkspaceID)
+		assert.Equal(t, string(PermissionWorkspaceUpdate), record.Permission)
+		assert.Equal(t, "127.0.0.1", record.IPAddress)
+		assert.Equal(t, 202, record.StatusCode)
+	})
+
+	t.Run("forbidden request", func(t *testing.T) {
+		_, err := viewerClient.UpdateWorkspace("installationid", &UpdateWorkspaceRequest{Version: utils.NewString("5.31.0")})
+		require.EqualError(t, err, "failed with status code 403: missing permission workspace:update")
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.Equal(t, "viewer", record.Actor)
+		assert.Equal(t, 403, record.StatusCode)
+	})
+
+	t.Run("unauthenticated request", func(t *testing.T) {
+		_, err := NewClientWithToken(ts.URL, "invalid").UpdateWorkspace("installationid", &UpdateWorkspaceRequest{Version: utils.NewString("5.31.0")})
+		require.EqualError(t, err, "failed with status code 401: failed to authenticate request: invalid api token")
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.Empty(t, record.Actor)
+		assert.Equal(t, "installationid", record.WorkspaceID)
+		assert.Equal(t, 401, record.StatusCode)
+	})
+
+	t.Run("viewer cannot read audit log", func(t *testing.T) {
+		_, err := viewerClient.GetAuditRecords(&AuditFilter{})
+		assert.EqualError(t, err, "failed with status code 403: missing permission audit:read")
+	})
+
+	t.Run("admin can read audit log", func(t *testing.T) {
+		records, err := adminClient.GetAuditRecords(&AuditFilter{Actor: "viewer"})
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, 403, records[0].StatusCode)
+	})
+
+	t.Run("invalid paging", func(t *testing.T) {
+		_, err := adminClient.GetAuditRecords(&AuditFilter{PerPage: 5000})
+		assert.EqualError(t, err, "failed with status code 400: per_page must be between 1 and 1000")
+	})
+}
+
+func TestAuditLogNotConfigured(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	router := mux.NewRouter()
+	Register(router, &Context{Logger: logger})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	records, err := client.GetAuditRecords(&AuditFilter{})
+	assert.EqualError(t, err, "failed with status code 501: audit log is not configured")
+	assert.Nil(t, records)
+}
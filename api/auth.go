@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AuthMethodToken is used for identities authenticated by a static API token.
+	AuthMethodToken = "token"
+	// AuthMethodOIDC is used for identities authenticated by an OIDC issued JWT.
+	AuthMethodOIDC = "oidc"
+)
+
+// Identity is the authenticated caller of an API request.
+type Identity struct {
+	Subject string   `json:"subject"`
+	Email   string   `json:"email,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Method  string   `json:"method"`
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// Name returns a human readable name for the identity, preferring the email address.
+func (i *Identity) Name() string {
+	if i == nil {
+		return ""
+	}
+	if i.Email != "" {
+		return i.Email
+	}
+
+	return i.Subject
+}
+
+// Authenticator authenticates API requests.
+type Authenticator interface {
+	// Authenticate returns the identity making the request, or an error if the
+	// request could not be authenticated.
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// errNoCredentials is returned when a request does not carry any credentials.
+var errNoCredentials = errors.New("no credentials provided")
+
+// bearerToken extracts the token from the Authorization header of the request.
+func bearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", errNoCredentials
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		return "", errors.New("authorization header must use the Bearer scheme")
+	}
+
+	return strings.TrimSpace(parts[1]), nil
+}
+
+// TokenAuthenticator authenticates requests bearing one of a fixed set of API tokens.
+type TokenAuthenticator struct {
+	tokens map[string]string
+}
+
+// NewTokenAuthenticator creates an authenticator from a map of subjects to their API tokens.
+func NewTokenAuthenticator(tokens map[string]string) *TokenAuthenticator {
+	return &TokenAuthenticator{tokens: tokens}
+}
+
+// ParseAPITokens parses a list of subject=token pairs.
+func ParseAPITokens(pairs []string) (map[string]string, error) {
+	tokens := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("api token %q must be in the form subject=token", pair)
+		}
+		if _, ok := tokens[parts[0]]; ok {
+			return nil, errors.Errorf("duplicate api token subject %s", parts[0])
+		}
+
+		tokens[parts[0]] = parts[1]
+	}
+
+	return tokens, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare against every token to avoid leaking which one matched through timing.
+	var subject string
+	for s, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			subject = s
+		}
+	}
+	if subject == "" {
+		return nil, errors.New("invalid api token")
+	}
+
+	return &Identity{Subject: subject, Method: AuthMethodToken}, nil
+}
+
+// OIDCAuthenticator authenticates requests bearing a JWT issued by an OIDC provider.
+type OIDCAuthenticator struct {
+	verifier    *oidc.IDTokenVerifier
+	groupsClaim string
+}
+
+// OIDCConfig holds the settings used to validate OIDC issued tokens.
+type OIDCConfig struct {
+	// IssuerURL is the URL of the OIDC provider, used for discovery and to validate the iss claim.
+	IssuerURL string
+	// ClientID is the expected aud claim of the tokens.
+	ClientID string
+	// GroupsClaim is the name of the claim listing the groups of the subject.
+	GroupsClaim string
+}
+
+// NewOIDCAuthenticator creates an authenticator by discovering the configuration of the given issuer.
+func NewOIDCAuthenticator(config OIDCConfig) (*OIDCAuthenticator, error) {
+	// The provider keeps this context to refresh the signing keys of the
+	// issuer, so it must outlive the discovery request.
+	ctx := oidc.ClientContext(context.Background(), &http.Client{Timeout: 30 * time.Second})
+
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discover oidc provider")
+	}
+
+	groupsClaim := config.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = "groups"
+	}
+
+	return &OIDCAuthenticator{
+		verifier:    provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		groupsClaim: groupsClaim,
+	}, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	rawToken, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.verifier.Verify(r.Context(), rawToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid token")
+	}
+
+	claims := map[string]interface{}{}
+	err = token.Claims(&claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse token claims")
+	}
+
+	identity := &Identity{Subject: token.Subject, Method: AuthMethodOIDC}
+	if email, ok := claims["email"].(string); ok {
+		identity.Email = email
+	}
+	if groups, ok := claims[a.groupsClaim].([]interface{}); ok {
+		for _, group := range groups {
+			if groupName, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, groupName)
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+// ChainAuthenticator tries each of its authenticators in turn, returning the
+// first successfully authenticated identity.
+type ChainAuthenticator []Authenticator
+
+// Authenticate implements Authenticator.
+func (a ChainAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if len(a) == 0 {
+		return nil, errors.New("no authenticators configured")
+	}
+
+	var errs []string
+	for _, authenticator := range a {
+		identity, err := authenticator.Authenticate(r)
+		if err == nil {
+			return identity, nil
+		}
+		if err == errNoCredentials {
+			return nil, err
+		}
+		errs = append(errs, err.Error())
+	}
+
+	return nil, errors.New(strings.Join(errs, "; "))
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func makeAuthenticatedRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestParseAPITokens(t *testing.T) {
+	tokens, err := ParseAPITokens([]string{"alice=token1", "bob=token=2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"alice": "token1", "bob": "token=2"}, tokens)
+
+	_, err = ParseAPITokens([]string{"alice"})
+	assert.Error(t, err)
+
+	_, err = ParseAPITokens([]string{"=token"})
+	assert.Error(t, err)
+
+	_, err = ParseAPITokens([]string{"alice=token1", "alice=token2"})
+	assert.Error(t, err)
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	authenticator := NewTokenAuthenticator(map[string]string{"alice": "token1"})
+
+	t.Run("valid token", func(t *testing.T) {
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest("token1"))
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{Subject: "alice", Method: AuthMethodToken}, identity)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest("token2"))
+		assert.Error(t, err)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(""))
+		assert.Equal(t, errNoCredentials, err)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("wrong scheme", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth("alice", "token1")
+		identity, err := authenticator.Authenticate(r)
+		assert.Error(t, err)
+		assert.Nil(t, identity)
+	})
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	issuer := testlib.NewOIDCIssuer(t)
+	defer issuer.Close()
+
+	authenticator, err := NewOIDCAuthenticator(OIDCConfig{IssuerURL: issuer.URL, ClientID: "pillar"})
+	require.NoError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		token := issuer.Token("subject", "pillar", map[string]interface{}{
+			"email":  "alice@mattermost.com",
+			"groups": []string{"support"},
+		})
+
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(token))
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{Subject: "subject", Email: "alice@mattermost.com", Groups: []string{"support"}, Method: AuthMethodOIDC}, identity)
+		assert.Equal(t, "alice@mattermost.com", identity.Name())
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(issuer.Token("subject", "other", nil)))
+		assert.Error(t, err)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(issuer.ExpiredToken("subject", "pillar")))
+		assert.Error(t, err)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("token from another issuer", func(t *testing.T) {
+		otherIssuer := testlib.NewOIDCIssuer(t)
+		defer otherIssuer.Close()
+
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(otherIssuer.Token("subject", "pillar", nil)))
+		assert.Error(t, err)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("custom groups claim", func(t *testing.T) {
+		authenticator, err := NewOIDCAuthenticator(OIDCConfig{IssuerURL: issuer.URL, ClientID: "pillar", GroupsClaim: "roles"})
+		require.NoError(t, err)
+
+		identity, err := authenticator.Authenticate(makeAuthenticatedRequest(issuer.Token("subject", "pillar", map[string]interface{}{"roles": []string{"admin"}})))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, identity.Groups)
+		assert.Equal(t, "subject", identity.Name())
+	})
+
+	t.Run("unreachable issuer", func(t *testing.T) {
+		_, err := NewOIDCAuthenticator(OIDCConfig{IssuerURL: "http://127.0.0.1:0", ClientID: "pillar"})
+		assert.Error(t, err)
+	})
+}
+
+func TestChainAuthenticator(t *testing.T) {
+	issuer := testlib.NewOIDCIssuer(t)
+	defer issuer.Close()
+
+	oidcAuthenticator, err := NewOIDCAuthenticator(OIDCConfig{IssuerURL: issuer.URL, ClientID: "pillar"})
+	require.NoError(t, err)
+
+	authenticator := ChainAuthenticator{NewTokenAuthenticator(map[string]string{"alice": "token1"}), oidcAuthenticator}
+
+	identity, err := authenticator.Authenticate(makeAuthenticatedRequest("token1"))
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodToken, identity.Method)
+
+	identity, err = authenticator.Authenticate(makeAuthenticatedRequest(issuer.Token("bob", "pillar", nil)))
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodOIDC, identity.Method)
+
+	_, err = authenticator.Authenticate(makeAuthenticatedRequest("junk"))
+	assert.Error(t, err)
+
+	_, err = authenticator.Authenticate(makeAuthenticatedRequest(""))
+	assert.Equal(t, errNoCredentials, err)
+
+	_, err = ChainAuthenticator{}.Authenticate(makeAuthenticatedRequest("token1"))
+	assert.Error(t, err)
+}
+
+func TestAuthentication(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:        logger,
+		CloudClient:   mockCloudClient,
+		Authenticator: NewTokenAuthenticator(map[string]string{"alice": "token1"}),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("no credentials", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/api/v1/workspaces/list", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		client := NewClientWithToken(ts.URL, "token2")
+		workspaces, err := client.ListWorkspaces(&cloud.GetInstallationsRequest{})
+		assert.EqualError(t, err, "failed with status code 401: failed to authenticate request: invalid api token")
+		assert.Nil(t, workspaces)
+	})
+
+	t.Run("valid credentials", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallations(gomock.Any(), gomock.Any()).Times(1).Return([]*cloud.InstallationDTO{}, nil)
+
+		client := NewClientWithToken(ts.URL, "token1")
+		workspaces, err := client.ListWorkspaces(&cloud.GetInstallationsRequest{})
+		assert.NoError(t, err)
+		assert.NotNil(t, workspaces)
+	})
+
+	t.Run("static routes are not authenticated", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/robots.txt")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores responses of the cloud provisioner so that repeated lookups
+// of the same workspace do not reach the provisioner and its clusters.
+type Cache interface {
+	// Get returns the entry stored under the given key, or nil if there is none.
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	// Set stores the entry under the given key until the ttl expires.
+	Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration) error
+	// Delete removes the entries stored under the given keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// CacheEntry is a cached value along with when it was fetched.
+type CacheEntry struct {
+	Value []byte
+	// CachedAt is the time in milliseconds at which the value was fetched.
+	CachedAt int64
+}
+
+// MemoryCache is a Cache held in the memory of a single server.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+type memoryCacheEntry struct {
+	entry     *CacheEntry
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the entry stored under the given key, or nil if there is none.
+func (c *MemoryCache) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !c.now().Before(cached.expiresAt) {
+		delete(c.entries, key)
+		return nil, nil
+	}
+
+	return cached.entry, nil
+}
+
+// Set stores the entry under the given key until the ttl expires.
+func (c *MemoryCache) Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Expired entries are otherwise only dropped when read again.
+	now := c.now()
+	for existingKey, cached := range c.entries {
+		if !now.Before(cached.expiresAt) {
+			delete(c.entries, existingKey)
+		}
+	}
+
+	c.entries[key] = memoryCacheEntry{entry: entry, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+// Delete removes the entries stored under the given keys.
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+
+	return nil
+}
+
+type cacheContextKey int
+
+const (
+	cacheRefreshContextKey cacheContextKey = iota
+	cacheUsageContextKey
+)
+
+// withCacheRefresh returns a context whose provisioner lookups bypass the
+// cache, storing the fresh values for subsequent lookups instead.
+func withCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheRefreshContextKey, true)
+}
+
+func isCacheRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(cacheRefreshContextKey).(bool)
+	return refresh
+}
+
+// cacheUsage tracks the oldest cached value used to answer a request.
+type cacheUsage struct {
+	mu       sync.Mutex
+	cachedAt int64
+}
+
+func (u *cacheUsage) add(entry *CacheEntry) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.cachedAt == 0 || entry.CachedAt < u.cachedAt {
+		u.cachedAt = entry.CachedAt
+	}
+}
+
+// CachedAt returns the time in milliseconds at which the oldest cached value
+// was fetched, or 0 if no cached value was used.
+func (u *cacheUsage) CachedAt() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.cachedAt
+}
+
+// withCacheUsage returns a context that records the cached values used by
+// provisioner lookups made with it.
+func withCacheUsage(ctx context.Context) (context.Context, *cacheUsage) {
+	usage := &cacheUsage{}
+	return context.WithValue(ctx, cacheUsageContextKey, usage), usage
+}
+
+func cacheUsageFromContext(ctx context.Context) *cacheUsage {
+	usage, _ := ctx.Value(cacheUsageContextKey).(*cacheUsage)
+	return usage
+}
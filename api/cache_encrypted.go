@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EncryptedCache is a Cache encrypting the values it stores in another Cache.
+// Cached provisioner responses hold secrets such as database credentials,
+// licenses and environment variables, so a cache shared between servers
+// must not store them in cleartext.
+type EncryptedCache struct {
+	cache Cache
+	aead  cipher.AEAD
+}
+
+// NewEncryptedCache creates a cache encrypting values with AES-GCM, using a
+// key derived from the given secret, before storing them in the given cache.
+func NewEncryptedCache(cache Cache, secret string) (*EncryptedCache, error) {
+	if secret == "" {
+		return nil, errors.New("cache encryption key must not be empty")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cache cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cache cipher")
+	}
+
+	return &EncryptedCache{cache: cache, aead: aead}, nil
+}
+
+// Get returns the decrypted entry stored under the given key, or nil if there is none.
+func (c *EncryptedCache) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	entry, err := c.cache.Get(ctx, key)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(entry.Value) < nonceSize {
+		return nil, errors.New("failed to decrypt cache entry: value is too short")
+	}
+
+	// The key is authenticated along with the value, so that an entry cannot
+	// be passed off as the value of another key.
+	value, err := c.aead.Open(nil, entry.Value[:nonceSize], entry.Value[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt cache entry")
+	}
+
+	return &CacheEntry{Value: value, CachedAt: entry.CachedAt}, nil
+}
+
+// Set encrypts the entry and stores it under the given key until the ttl expires.
+func (c *EncryptedCache) Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration) error {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate cache nonce")
+	}
+
+	return c.cache.Set(ctx, key, &CacheEntry{
+		Value:    c.aead.Seal(nonce, nonce, entry.Value, []byte(key)),
+		CachedAt: entry.CachedAt,
+	}, ttl)
+}
+
+// Delete removes the entries stored under the given keys.
+func (c *EncryptedCache) Delete(ctx context.Context, keys ...string) error {
+	return c.cache.Delete(ctx, keys...)
+}
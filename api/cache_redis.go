@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const redisCacheKeyPrefix = "pillar:cache:"
+
+// RedisCache is a Cache stored in Redis, allowing several servers to share it.
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a cache backed by the Redis server at the given URL,
+// e.g. redis://:password@host:6379/0.
+func NewRedisCache(redisURL string) (*RedisCache, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse redis url")
+	}
+
+	return &RedisCache{client: redis.NewClient(options)}, nil
+}
+
+// Ping checks that the Redis server can be reached.
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Close closes the connections to the Redis server.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// Get returns the entry stored under the given key, or nil if there is none.
+func (c *RedisCache) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	b, err := c.client.Get(ctx, redisCacheKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cache entry")
+	}
+
+	entry := &CacheEntry{}
+	err = json.Unmarshal(b, entry)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode cache entry")
+	}
+
+	return entry, nil
+}
+
+// Set stores the entry under the given key until the ttl expires.
+func (c *RedisCache) Set(ctx context.Context, key string, entry *CacheEntry, ttl time.Duration) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cache entry")
+	}
+
+	err = c.client.Set(ctx, redisCacheKeyPrefix+key, b, ttl).Err()
+	if err != nil {
+		return errors.Wrap(err, "failed to set cache entry")
+	}
+
+	return nil
+}
+
+// Delete removes the entries stored under the given keys.
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = redisCacheKeyPrefix + key
+	}
+
+	err := c.client.Del(ctx, prefixedKeys...).Err()
+	if err != nil {
+		return errors.Wrap(err, "failed to delete cache entries")
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+
+	entry, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = cache.Set(ctx, "key", &CacheEntry{Value: []byte("value"), CachedAt: 1}, time.Minute)
+	require.NoError(t, err)
+	err = cache.Set(ctx, "other", &CacheEntry{Value: []byte("other"), CachedAt: 1}, time.Hour)
+	require.NoError(t, err)
+
+	entry, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, &CacheEntry{Value: []byte("value"), CachedAt: 1}, entry)
+
+	now = now.Add(time.Minute)
+	entry, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = cache.Delete(ctx, "other", "missing")
+	require.NoError(t, err)
+	entry, err = cache.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	cache, err := NewRedisCache(fmt.Sprintf("redis://%s/0", server.Addr()))
+	require.NoError(t, err)
+	defer cache.Close()
+	require.NoError(t, cache.Ping(ctx))
+
+	entry, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = cache.Set(ctx, "key", &CacheEntry{Value: []byte("value"), CachedAt: 1}, time.Minute)
+	require.NoError(t, err)
+	err = cache.Set(ctx, "other", &CacheEntry{Value: []byte("other"), CachedAt: 1}, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, server.Exists("pillar:cache:key"))
+
+	entry, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, &CacheEntry{Value: []byte("value"), CachedAt: 1}, entry)
+
+	server.FastForward(time.Minute)
+	entry, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = cache.Delete(ctx, "other", "missing")
+	require.NoError(t, err)
+	entry, err = cache.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := NewRedisCache("http://localhost")
+		assert.Error(t, err)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server.Close()
+		_, err := cache.Get(ctx, "key")
+		assert.Error(t, err)
+	})
+}
+
+func TestEncryptedCache(t *testing.T) {
+	ctx := context.Background()
+	memoryCache := NewMemoryCache()
+
+	_, err := NewEncryptedCache(memoryCache, "")
+	assert.Error(t, err)
+
+	cache, err := NewEncryptedCache(memoryCache, "secret")
+	require.NoError(t, err)
+
+	entry, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	err = cache.Set(ctx, "key", &CacheEntry{Value: []byte("value"), CachedAt: 1}, time.Minute)
+	require.NoError(t, err)
+
+	stored, err := memoryCache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Value), "value")
+	assert.Equal(t, int64(1), stored.CachedAt)
+
+	entry, err = cache.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, &CacheEntry{Value: []byte("value"), CachedAt: 1}, entry)
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherCache, err := NewEncryptedCache(memoryCache, "other")
+		require.NoError(t, err)
+
+		_, err = otherCache.Get(ctx, "key")
+		assert.Error(t, err)
+	})
+
+	t.Run("entry moved to another key", func(t *testing.T) {
+		err := memoryCache.Set(ctx, "moved", stored, time.Minute)
+		require.NoError(t, err)
+
+		_, err = cache.Get(ctx, "moved")
+		assert.Error(t, err)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		err := cache.Delete(ctx, "key")
+		require.NoError(t, err)
+
+		entry, err := cache.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+	})
+}
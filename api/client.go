@@ -10,6 +10,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -21,6 +23,7 @@ type Client struct {
 	address    string
 	headers    map[string]string
 	httpClient *http.Client
+	refresh    bool
 }
 
 // NewClient creates a client to the provisioning server at the given address.
@@ -42,6 +45,35 @@ func NewClientWithHeaders(address string, headers map[string]string) *Client {
 	}
 }
 
+// NewClientWithToken creates a client to the provisioning server at the given
+// address that authenticates with the given bearer token.
+func NewClientWithToken(address, token string) *Client {
+	return NewClientWithHeaders(address, map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", token),
+	})
+}
+
+// SetJustification sets the reason, and optionally the support ticket, sent
+// with every subsequent request. An empty reason clears the justification.
+func (c *Client) SetJustification(reason, ticketID string) {
+	delete(c.headers, HeaderJustification)
+	delete(c.headers, HeaderTicketID)
+
+	if reason == "" {
+		return
+	}
+	c.headers[HeaderJustification] = reason
+	if ticketID != "" {
+		c.headers[HeaderTicketID] = ticketID
+	}
+}
+
+// SetRefresh sets whether subsequent lookups bypass the cache of the server
+// and fetch fresh data from the provisioner.
+func (c *Client) SetRefresh(refresh bool) {
+	c.refresh = refresh
+}
+
 // closeBody ensures the Body of an http.Response is properly closed.
 func closeBody(r *http.Response) {
 	if r.Body != nil {
@@ -50,11 +82,37 @@ func closeBody(r *http.Response) {
 	}
 }
 
+// maxErrorResponseSize limits how much of an error response is read for its message.
+const maxErrorResponseSize = 64 * 1024
+
+// errorFromResponse returns an error for an unexpected response, including the
+// message given by the server, if any.
+func errorFromResponse(resp *http.Response) error {
+	apiError := &Error{}
+	err := decodeJSON(apiError, io.LimitReader(resp.Body, maxErrorResponseSize))
+	if err == nil && apiError.Message != "" {
+		return errors.Errorf("failed with status code %d: %s", resp.StatusCode, apiError.Message)
+	}
+
+	return errors.Errorf("failed with status code %d", resp.StatusCode)
+}
+
 func (c *Client) buildURL(urlPath string, args ...interface{}) string {
 	return fmt.Sprintf("%s%s", c.address, fmt.Sprintf(urlPath, args...))
 }
 
 func (c *Client) doGet(u string) (*http.Response, error) {
+	if c.refresh {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse url")
+		}
+		q := parsed.Query()
+		q.Set("refresh", "true")
+		parsed.RawQuery = q.Encode()
+		u = parsed.String()
+	}
+
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create http request")
@@ -101,14 +159,38 @@ func (c *Client) doPut(u string, request interface{}) (*http.Response, error) {
 	return c.httpClient.Do(req)
 }
 
-func (c *Client) doDelete(u string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodDelete, u, nil)
+func (c *Client) doPatch(u string, request interface{}) (*http.Response, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request")
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, u, bytes.NewReader(requestBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create http request")
+	}
+	for k, v := range c.headers {
+		req.Header.Add(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) doDelete(u string, request interface{}) (*http.Response, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request")
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, u, bytes.NewReader(requestBytes))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create http request")
 	}
 	for k, v := range c.headers {
 		req.Header.Add(k, v)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
 	return c.httpClient.Do(req)
 }
@@ -126,6 +208,12 @@ func workspacesFromReader(reader io.Reader) ([]*Workspace, error) {
 
 // ListWorkspaces lists workspaces that match the provided properties.
 func (c *Client) ListWorkspaces(request *cloud.GetInstallationsRequest) ([]*Workspace, error) {
+	return c.ListWorkspacesWithState(&ListWorkspacesRequest{GetInstallationsRequest: *request})
+}
+
+// ListWorkspacesWithState lists workspaces that match the provided properties,
+// including their state.
+func (c *Client) ListWorkspacesWithState(request *ListWorkspacesRequest) ([]*Workspace, error) {
 	resp, err := c.doPost(c.buildURL("/api/v1/workspaces/list"), request)
 	if err != nil {
 		return nil, err
@@ -137,8 +225,63 @@ func (c *Client) ListWorkspaces(request *cloud.GetInstallationsRequest) ([]*Work
 		return workspacesFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// SearchWorkspaces searches the workspace index of the server.
+func (c *Client) SearchWorkspaces(search *WorkspaceSearch) ([]*Workspace, error) {
+	query := url.Values{}
+	optionalParams := map[string]string{
+		"q":         search.Query,
+		"state":     search.State,
+		"version":   search.Version,
+		"size":      search.Size,
+		"edition":   search.Edition,
+		"database":  search.Database,
+		"filestore": search.Filestore,
+		"sort":      search.Sort,
+	}
+	for name, value := range optionalParams {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if search.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+	if search.Descending {
+		query.Set("order", "desc")
+	}
+	query.Set("page", strconv.Itoa(search.Page))
+	if search.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(search.PerPage))
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/search?%s", query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return workspacesFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func workspaceFromReader(reader io.Reader) (*Workspace, error) {
+	workspace := &Workspace{}
+
+	err := decodeJSON(workspace, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
 	}
+
+	return workspace, nil
 }
 
 func workspaceDetailedFromReader(reader io.Reader) (*WorkspaceDetailed, error) {
@@ -153,8 +296,20 @@ func workspaceDetailedFromReader(reader io.Reader) (*WorkspaceDetailed, error) {
 }
 
 // GetWorkspace fetches a single workspace with detailed data and context.
+// Sections of the workspace that the server failed to fetch are reported by
+// WorkspaceDetailed.Errors rather than failing the call.
 func (c *Client) GetWorkspace(id string) (*WorkspaceDetailed, error) {
-	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s", id))
+	return c.getWorkspace(c.buildURL("/api/v1/workspaces/%s", id))
+}
+
+// GetWorkspaceUnredacted fetches a single workspace with detailed data and
+// context, including the cleartext values of sensitive config settings.
+func (c *Client) GetWorkspaceUnredacted(id string) (*WorkspaceDetailed, error) {
+	return c.getWorkspace(c.buildURL("/api/v1/workspaces/%s?unredacted=true", id))
+}
+
+func (c *Client) getWorkspace(u string) (*WorkspaceDetailed, error) {
+	resp, err := c.doGet(u)
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +320,703 @@ func (c *Client) GetWorkspace(id string) (*WorkspaceDetailed, error) {
 		return workspaceDetailedFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// UpdateWorkspace changes the version, image or size of a workspace.
+func (c *Client) UpdateWorkspace(id string, request *UpdateWorkspaceRequest) (*Workspace, error) {
+	resp, err := c.doPut(c.buildURL("/api/v1/workspaces/%s", id), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return workspaceFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// DeleteWorkspace requests the deletion of a workspace, returning it in its
+// resulting state. The request must confirm the DNS of the workspace.
+func (c *Client) DeleteWorkspace(id string, request *DeleteWorkspaceRequest) (*Workspace, error) {
+	resp, err := c.doDelete(c.buildURL("/api/v1/workspaces/%s", id), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return workspaceFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// HibernateWorkspace hibernates a stable workspace, returning it in its resulting state.
+func (c *Client) HibernateWorkspace(id string) (*Workspace, error) {
+	return c.requestWorkspaceState(c.buildURL("/api/v1/workspaces/%s/hibernate", id))
+}
+
+// WakeupWorkspace wakes a hibernating workspace, returning it in its resulting state.
+func (c *Client) WakeupWorkspace(id string) (*Workspace, error) {
+	return c.requestWorkspaceState(c.buildURL("/api/v1/workspaces/%s/wakeup", id))
+}
+
+func (c *Client) requestWorkspaceState(u string) (*Workspace, error) {
+	resp, err := c.doPost(u, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return workspaceFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// ListWorkspaceUsers lists the given page of users of a workspace. If a query
+// is given, the users whose ID, email or username exactly equals one of its
+// words are returned instead, and the page is ignored.
+func (c *Client) ListWorkspaceUsers(id, q string, page, perPage int) ([]*User, error) {
+	query := url.Values{}
+	if q != "" {
+		query.Set("q", q)
+	} else {
+		query.Set("page", strconv.Itoa(page))
+		if perPage > 0 {
+			query.Set("per_page", strconv.Itoa(perPage))
+		}
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/users?%s", id, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return usersFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetWorkspaceUser fetches a single user of a workspace by ID, email or username.
+func (c *Client) GetWorkspaceUser(id, user string) (*User, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/users/%s", id, url.PathEscape(user)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return userFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// RunWorkspaceUserAction runs an account recovery action on a user of a
+// workspace, given by ID, email or username.
+func (c *Client) RunWorkspaceUserAction(id, user string, request *UserActionRequest) (*UserActionResult, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/workspaces/%s/users/%s/actions", id, url.PathEscape(user)), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return userActionResultFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func userActionResultFromReader(reader io.Reader) (*UserActionResult, error) {
+	result := &UserActionResult{}
+
+	err := decodeJSON(result, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListWorkspaceTeams lists the teams of a workspace, optionally filtered by a
+// partial name or display name. The members of each team are only counted if
+// memberCounts is true.
+func (c *Client) ListWorkspaceTeams(id, q string, memberCounts bool) ([]*Team, error) {
+	query := url.Values{}
+	if q != "" {
+		query.Set("q", q)
+	}
+	if memberCounts {
+		query.Set("member_counts", "true")
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/teams?%s", id, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return teamsFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// ListWorkspaceTeamChannels lists the channels of a team of a workspace, given
+// by ID or name, optionally filtered by a partial name or display name.
+func (c *Client) ListWorkspaceTeamChannels(id, team, q string) ([]*Channel, error) {
+	query := url.Values{}
+	if q != "" {
+		query.Set("q", q)
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/teams/%s/channels?%s", id, url.PathEscape(team), query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return channelsFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// GetWorkspaceChannel fetches a single channel of a workspace, archived or not,
+// by ID, or by name if the team it belongs to is given.
+func (c *Client) GetWorkspaceChannel(id, channel, team string) (*Channel, error) {
+	query := url.Values{}
+	if team != "" {
+		query.Set("team", team)
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/channels/%s?%s", id, url.PathEscape(channel), query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return channelFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// UnarchiveWorkspaceChannel restores an archived channel of a workspace. The
+// channel is given by ID, or by name within the given team.
+func (c *Client) UnarchiveWorkspaceChannel(id, channel, team string) (*ChannelActionResult, error) {
+	return c.changeWorkspaceChannel(id, channel, team, ChannelActionUnarchive, struct{}{})
+}
+
+// MoveWorkspaceChannel moves a channel of a workspace to another team.
+func (c *Client) MoveWorkspaceChannel(id, channel, team string, request *MoveChannelRequest) (*ChannelActionResult, error) {
+	return c.changeWorkspaceChannel(id, channel, team, ChannelActionMove, request)
+}
+
+// RenameWorkspaceChannel changes the name or display name of a channel of a workspace.
+func (c *Client) RenameWorkspaceChannel(id, channel, team string, request *RenameChannelRequest) (*ChannelActionResult, error) {
+	return c.changeWorkspaceChannel(id, channel, team, ChannelActionRename, request)
+}
+
+func (c *Client) changeWorkspaceChannel(id, channel, team, action string, request interface{}) (*ChannelActionResult, error) {
+	query := url.Values{}
+	if team != "" {
+		query.Set("team", team)
+	}
+
+	resp, err := c.doPost(c.buildURL("/api/v1/workspaces/%s/channels/%s/%s?%s", id, url.PathEscape(channel), action, query.Encode()), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return channelActionResultFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func channelActionResultFromReader(reader io.Reader) (*ChannelActionResult, error) {
+	result := &ChannelActionResult{}
+
+	err := decodeJSON(result, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetWorkspaceHealth runs the health checks of a workspace.
+func (c *Client) GetWorkspaceHealth(id string) (*WorkspaceHealth, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/health", id))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return workspaceHealthFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func workspaceHealthFromReader(reader io.Reader) (*WorkspaceHealth, error) {
+	health := &WorkspaceHealth{}
+
+	err := decodeJSON(health, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// DownloadWorkspaceSupportPacket gathers a support packet of a workspace,
+// writing the zip file to the given writer.
+func (c *Client) DownloadWorkspaceSupportPacket(id string, out io.Writer) error {
+	resp, err := c.doPost(c.buildURL("/api/v1/workspaces/%s/support-packet", id), struct{}{})
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		_, err = io.Copy(out, resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to download support packet")
+		}
+		return nil
+
+	default:
+		return errorFromResponse(resp)
+	}
+}
+
+// ListWorkspacePlugins lists the enabled and disabled plugins of a workspace.
+func (c *Client) ListWorkspacePlugins(id string) ([]*Plugin, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/workspaces/%s/plugins", id))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return pluginsFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// RunWorkspacePluginAction enables, disables or installs a plugin of a workspace.
+func (c *Client) RunWorkspacePluginAction(id, plugin string, request *PluginActionRequest) (*PluginActionResult, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/workspaces/%s/plugins/%s/actions", id, url.PathEscape(plugin)), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return pluginActionResultFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// ListPluginWorkspaces searches a page of cluster installations for the
+// workspaces that have a plugin installed, optionally at the given version.
+func (c *Client) ListPluginWorkspaces(plugin, version string, page, perPage int) (*PluginWorkspaces, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	if perPage > 0 {
+		query.Set("per_page", strconv.Itoa(perPage))
+	}
+	if version != "" {
+		query.Set("version", version)
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/plugins/%s/workspaces?%s", url.PathEscape(plugin), query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return pluginWorkspacesFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func pluginsFromReader(reader io.Reader) ([]*Plugin, error) {
+	plugins := []*Plugin{}
+
+	err := decodeJSON(&plugins, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+func pluginActionResultFromReader(reader io.Reader) (*PluginActionResult, error) {
+	result := &PluginActionResult{}
+
+	err := decodeJSON(result, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func pluginWorkspacesFromReader(reader io.Reader) (*PluginWorkspaces, error) {
+	pluginWorkspaces := &PluginWorkspaces{}
+
+	err := decodeJSON(pluginWorkspaces, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return pluginWorkspaces, nil
+}
+
+func teamsFromReader(reader io.Reader) ([]*Team, error) {
+	teams := []*Team{}
+
+	err := decodeJSON(&teams, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func channelsFromReader(reader io.Reader) ([]*Channel, error) {
+	channels := []*Channel{}
+
+	err := decodeJSON(&channels, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+func channelFromReader(reader io.Reader) (*Channel, error) {
+	channel := &Channel{}
+
+	err := decodeJSON(channel, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+func usersFromReader(reader io.Reader) ([]*User, error) {
+	users := []*User{}
+
+	err := decodeJSON(&users, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func userFromReader(reader io.Reader) (*User, error) {
+	user := &User{}
+
+	err := decodeJSON(user, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func workspaceConfigChangeFromReader(reader io.Reader) (*WorkspaceConfigChange, error) {
+	change := &WorkspaceConfigChange{}
+
+	err := decodeJSON(change, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// PatchWorkspaceConfig changes a single config setting of a workspace.
+func (c *Client) PatchWorkspaceConfig(id string, request *PatchWorkspaceConfigRequest) (*WorkspaceConfigChange, error) {
+	resp, err := c.doPatch(c.buildURL("/api/v1/workspaces/%s/config", id), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return workspaceConfigChangeFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func auditRecordsFromReader(reader io.Reader) ([]*AuditRecord, error) {
+	records := []*AuditRecord{}
+
+	err := decodeJSON(&records, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// GetAuditRecords lists audit records that match the provided filter.
+func (c *Client) GetAuditRecords(filter *AuditFilter) ([]*AuditRecord, error) {
+	query := url.Values{}
+	if filter.Actor != "" {
+		query.Set("actor", filter.Actor)
+	}
+	if filter.WorkspaceID != "" {
+		query.Set("workspace", filter.WorkspaceID)
+	}
+	if filter.Since > 0 {
+		query.Set("since", strconv.FormatInt(filter.Since, 10))
+	}
+	if filter.Until > 0 {
+		query.Set("until", strconv.FormatInt(filter.Until, 10))
+	}
+	query.Set("page", strconv.Itoa(filter.Page))
+	if filter.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(filter.PerPage))
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/audit?%s", query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return auditRecordsFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func groupsFromReader(reader io.Reader) ([]*Group, error) {
+	groups := []*Group{}
+
+	err := decodeJSON(&groups, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// ListGroups lists groups.
+func (c *Client) ListGroups(request *cloud.GetGroupsRequest) ([]*Group, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(request.Page))
+	if request.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(request.PerPage))
+	}
+	if request.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/groups?%s", query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return groupsFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func groupDetailedFromReader(reader io.Reader) (*GroupDetailed, error) {
+	group := &GroupDetailed{}
+
+	err := decodeJSON(group, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// GetGroup fetches a single group with the configuration it applies to its workspaces.
+func (c *Client) GetGroup(id string) (*GroupDetailed, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/groups/%s", id))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return groupDetailedFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+// ListGroupWorkspaces lists the workspaces that belong to a group. Only the
+// paging and deletion properties of the request are used.
+func (c *Client) ListGroupWorkspaces(id string, request *cloud.GetInstallationsRequest) ([]*Workspace, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(request.Page))
+	if request.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(request.PerPage))
+	}
+	if request.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/groups/%s/workspaces?%s", id, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return workspacesFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func clustersFromReader(reader io.Reader) ([]*Cluster, error) {
+	clusters := []*Cluster{}
+
+	err := decodeJSON(&clusters, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// ListClusters lists clusters.
+func (c *Client) ListClusters(request *cloud.GetClustersRequest) ([]*Cluster, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(request.Page))
+	if request.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(request.PerPage))
+	}
+	if request.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/clusters?%s", query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return clustersFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
+	}
+}
+
+func clusterWorkspacesFromReader(reader io.Reader) ([]*ClusterWorkspace, error) {
+	clusterWorkspaces := []*ClusterWorkspace{}
+
+	err := decodeJSON(&clusterWorkspaces, reader)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return clusterWorkspaces, nil
+}
+
+// ListClusterWorkspaces lists the workspaces hosted on a cluster. Only the
+// paging and deletion properties of the request are used.
+func (c *Client) ListClusterWorkspaces(id string, request *cloud.GetClusterInstallationsRequest) ([]*ClusterWorkspace, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(request.Page))
+	if request.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(request.PerPage))
+	}
+	if request.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+
+	resp, err := c.doGet(c.buildURL("/api/v1/clusters/%s/workspaces?%s", id, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return clusterWorkspacesFromReader(resp.Body)
+
+	default:
+		return nil, errorFromResponse(resp)
 	}
 }
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// Compile-time check to ensure CloudClient is implemented by ProvisionerClient
+var _ CloudClient = &ProvisionerClient{}
+
+// Compile-time check to ensure the calls mirrored by ProvisionerClient still exist in cloud.Client
+var _ provisionerAPI = &cloud.Client{}
+
+// provisionerAPI holds the calls of cloud.Client mirrored by ProvisionerClient.
+type provisionerAPI interface {
+	GetInstallation(string, *cloud.GetInstallationRequest) (*cloud.InstallationDTO, error)
+	GetInstallations(*cloud.GetInstallationsRequest) ([]*cloud.InstallationDTO, error)
+	UpdateInstallation(string, *cloud.PatchInstallationRequest) (*cloud.InstallationDTO, error)
+	HibernateInstallation(string) (*cloud.InstallationDTO, error)
+	WakeupInstallation(string) (*cloud.InstallationDTO, error)
+	DeleteInstallation(string) error
+	GetCluster(string) (*cloud.ClusterDTO, error)
+	GetClusters(*cloud.GetClustersRequest) ([]*cloud.ClusterDTO, error)
+	GetClusterInstallations(*cloud.GetClusterInstallationsRequest) ([]*cloud.ClusterInstallation, error)
+	ExecClusterInstallationCLI(string, string, []string) ([]byte, error)
+	GetGroup(string) (*cloud.Group, error)
+	GetGroups(*cloud.GetGroupsRequest) ([]*cloud.Group, error)
+}
+
+// CloudClientTimeouts bound the duration of each kind of call to the cloud
+// provisioner. A zero timeout leaves the call bounded only by its context.
+type CloudClientTimeouts struct {
+	// Get bounds fetching a single installation, group or cluster.
+	Get time.Duration
+	// List bounds listing installations, groups, clusters and cluster installations.
+	List time.Duration
+	// Exec bounds running commands against cluster installations, such as fetching or changing config.
+	Exec time.Duration
+	// Update bounds changing installations, including hibernating, waking and deleting them.
+	Update time.Duration
+}
+
+// DefaultCloudClientTimeouts are the timeouts used unless configured otherwise.
+var DefaultCloudClientTimeouts = CloudClientTimeouts{
+	Get:    15 * time.Second,
+	List:   30 * time.Second,
+	Exec:   60 * time.Second,
+	Update: 30 * time.Second,
+}
+
+// ProvisionerClient is a context-aware client to the cloud provisioner API. It
+// mirrors cloud.Client, but every call is cancelled along with its context and
+// bounded by the configured timeouts.
+//
+// cloud.Client can neither cancel its requests nor be given an http.Client
+// with a timeout, so wrapping it would leave every call that is given up on
+// running in the background. The requests and responses are still built and
+// decoded with the helpers of the cloud model.
+type ProvisionerClient struct {
+	address    string
+	headers    map[string]string
+	httpClient *http.Client
+	timeouts   CloudClientTimeouts
+}
+
+// NewProvisionerClient creates a client to the provisioning server at the given address.
+func NewProvisionerClient(address string, timeouts CloudClientTimeouts) *ProvisionerClient {
+	return &ProvisionerClient{
+		address:    address,
+		headers:    make(map[string]string),
+		httpClient: &http.Client{},
+		timeouts:   timeouts,
+	}
+}
+
+func (c *ProvisionerClient) buildURL(urlPath string, args ...interface{}) string {
+	return fmt.Sprintf("%s%s", c.address, fmt.Sprintf(urlPath, args...))
+}
+
+// withTimeout derives a context bounded by the given timeout, if any.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (c *ProvisionerClient) do(ctx context.Context, method, u string, request interface{}) (*http.Response, error) {
+	var body io.Reader
+	if request != nil {
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(requestBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create http request")
+	}
+	for k, v := range c.headers {
+		req.Header.Add(k, v)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func (c *ProvisionerClient) doGet(ctx context.Context, u string) (*http.Response, error) {
+	return c.do(ctx, http.MethodGet, u, nil)
+}
+
+// doWrite sends a request changing the provisioner. A change that was sent is
+// not undone by giving up on its response, so a write cancelled or timed out
+// before the provisioner responded is reported as having an unknown outcome
+// rather than as not performed.
+func (c *ProvisionerClient) doWrite(ctx context.Context, method, u string, request interface{}) (*http.Response, error) {
+	resp, err := c.do(ctx, method, u, request)
+	if err != nil && ctx.Err() != nil {
+		return nil, errors.Wrap(err, "provisioner did not respond in time, the change may still be applied")
+	}
+
+	return resp, err
+}
+
+// GetInstallation fetches the specified installation from the configured provisioning server.
+func (c *ProvisionerClient) GetInstallation(ctx context.Context, installationID string, request *cloud.GetInstallationRequest) (*cloud.InstallationDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Get)
+	defer cancel()
+
+	u, err := url.Parse(c.buildURL("/api/installation/%s", installationID))
+	if err != nil {
+		return nil, err
+	}
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.InstallationDTOFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetInstallations fetches the list of installations from the configured provisioning server.
+func (c *ProvisionerClient) GetInstallations(ctx context.Context, request *cloud.GetInstallationsRequest) ([]*cloud.InstallationDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.List)
+	defer cancel()
+
+	u, err := url.Parse(c.buildURL("/api/installations"))
+	if err != nil {
+		return nil, err
+	}
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.InstallationDTOsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// UpdateInstallation updates an installation on the configured provisioning server.
+func (c *ProvisionerClient) UpdateInstallation(ctx context.Context, installationID string, request *cloud.PatchInstallationRequest) (*cloud.InstallationDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Update)
+	defer cancel()
+
+	resp, err := c.doWrite(ctx, http.MethodPut, c.buildURL("/api/installation/%s/mattermost", installationID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return cloud.InstallationDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// HibernateInstallation puts an installation into hibernation on the configured provisioning server.
+func (c *ProvisionerClient) HibernateInstallation(ctx context.Context, installationID string) (*cloud.InstallationDTO, error) {
+	return c.requestInstallationState(ctx, c.buildURL("/api/installation/%s/hibernate", installationID))
+}
+
+// WakeupInstallation wakes an installation from hibernation on the configured provisioning server.
+func (c *ProvisionerClient) WakeupInstallation(ctx context.Context, installationID string) (*cloud.InstallationDTO, error) {
+	return c.requestInstallationState(ctx, c.buildURL("/api/installation/%s/wakeup", installationID))
+}
+
+// DeleteInstallation requests the deletion of an installation on the configured provisioning server.
+func (c *ProvisionerClient) DeleteInstallation(ctx context.Context, installationID string) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Update)
+	defer cancel()
+
+	resp, err := c.doWrite(ctx, http.MethodDelete, c.buildURL("/api/installation/%s", installationID), nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil
+
+	default:
+		return errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+func (c *ProvisionerClient) requestInstallationState(ctx context.Context, u string) (*cloud.InstallationDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Update)
+	defer cancel()
+
+	resp, err := c.doWrite(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return cloud.InstallationDTOFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetClusterInstallations fetches the list of cluster installations from the configured provisioning server.
+func (c *ProvisionerClient) GetClusterInstallations(ctx context.Context, request *cloud.GetClusterInstallationsRequest) ([]*cloud.ClusterInstallation, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.List)
+	defer cancel()
+
+	u, err := url.Parse(c.buildURL("/api/cluster_installations"))
+	if err != nil {
+		return nil, err
+	}
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.ClusterInstallationsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// ExecClusterInstallationCLI runs a valid exec command against a cluster installation.
+func (c *ProvisionerClient) ExecClusterInstallationCLI(ctx context.Context, clusterInstallationID, command string, subcommand []string) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Exec)
+	defer cancel()
+
+	send := c.doWrite
+	if isReadOnlyCommand(command, subcommand) {
+		send = c.do
+	}
+
+	resp, err := send(ctx, http.MethodPost, c.buildURL("/api/cluster_installation/%s/exec/%s", clusterInstallationID, command), subcommand)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	output, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read exec output")
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return output, nil
+
+	default:
+		return output, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetGroup fetches the specified group from the configured provisioning server.
+func (c *ProvisionerClient) GetGroup(ctx context.Context, groupID string) (*cloud.Group, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Get)
+	defer cancel()
+
+	resp, err := c.doGet(ctx, c.buildURL("/api/group/%s", groupID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.GroupFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetGroups fetches the list of groups from the configured provisioning server.
+func (c *ProvisionerClient) GetGroups(ctx context.Context, request *cloud.GetGroupsRequest) ([]*cloud.Group, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.List)
+	defer cancel()
+
+	u, err := url.Parse(c.buildURL("/api/groups"))
+	if err != nil {
+		return nil, err
+	}
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.GroupsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetCluster fetches the specified cluster from the configured provisioning server.
+func (c *ProvisionerClient) GetCluster(ctx context.Context, clusterID string) (*cloud.ClusterDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Get)
+	defer cancel()
+
+	resp, err := c.doGet(ctx, c.buildURL("/api/cluster/%s", clusterID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.ClusterDTOFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
+
+// GetClusters fetches the list of clusters from the configured provisioning server.
+func (c *ProvisionerClient) GetClusters(ctx context.Context, request *cloud.GetClustersRequest) ([]*cloud.ClusterDTO, error) {
+	ctx, cancel := withTimeout(ctx, c.timeouts.List)
+	defer cancel()
+
+	u, err := url.Parse(c.buildURL("/api/clusters"))
+	if err != nil {
+		return nil, err
+	}
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return cloud.ClusterDTOsFromReader(resp.Body)
+
+	default:
+		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// Compile-time check to ensure CloudClient is implemented by CachingCloudClient
+var _ CloudClient = &CachingCloudClient{}
+
+// CacheTTLs are how long each kind of provisioner lookup is cached. A zero
+// ttl disables caching of that kind of lookup.
+type CacheTTLs struct {
+	// Get applies to fetching a single installation, group or cluster.
+	Get time.Duration
+	// List applies to listing installations, groups, clusters and cluster installations.
+	List time.Duration
+	// Config applies to fetching the config of cluster installations.
+	Config time.Duration
+	// Plugins applies to listing the plugins of cluster installations.
+	Plugins time.Duration
+}
+
+// DefaultCacheTTLs are the cache ttls used unless configured otherwise.
+var DefaultCacheTTLs = CacheTTLs{
+	Get:     time.Minute,
+	List:    30 * time.Second,
+	Config:  time.Minute,
+	Plugins: 5 * time.Minute,
+}
+
+// CachingCloudClient is a CloudClient that caches the lookups of another
+// CloudClient. Changes are always passed through and invalidate the cached
+// values they affect.
+type CachingCloudClient struct {
+	client CloudClient
+	cache  Cache
+	ttls   CacheTTLs
+	logger logrus.FieldLogger
+	now    func() time.Time
+}
+
+// NewCachingCloudClient creates a CloudClient caching the lookups of the given client.
+func NewCachingCloudClient(client CloudClient, cache Cache, ttls CacheTTLs, logger logrus.FieldLogger) *CachingCloudClient {
+	return &CachingCloudClient{
+		client: client,
+		cache:  cache,
+		ttls:   ttls,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// cached loads the value stored under the given key into value, falling back
+// to fetch and storing its result when there is no cached value or a refresh
+// is requested. Cache failures are logged rather than failing the lookup.
+func (c *CachingCloudClient) cached(ctx context.Context, key string, ttl time.Duration, value interface{}, fetch func() (interface{}, error)) error {
+	if ttl > 0 && !isCacheRefresh(ctx) {
+		entry, err := c.cache.Get(ctx, key)
+		if err != nil {
+			c.logger.WithError(err).WithField("key", key).Warn("Failed to read from cache")
+		}
+		if entry != nil {
+			err = json.Unmarshal(entry.Value, value)
+			if err == nil {
+				if usage := cacheUsageFromContext(ctx); usage != nil {
+					usage.add(entry)
+				}
+				return nil
+			}
+			c.logger.WithError(err).WithField("key", key).Warn("Failed to decode cached value")
+		}
+	}
+
+	fetched, err := fetch()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(fetched)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode value")
+	}
+
+	// Values that were not found are not cached, so that they show up as
+	// soon as they are created.
+	if ttl > 0 && string(b) != "null" {
+		entry := &CacheEntry{Value: b, CachedAt: c.now().UnixNano() / int64(time.Millisecond)}
+		err = c.cache.Set(ctx, key, entry, ttl)
+		if err != nil {
+			c.logger.WithError(err).WithField("key", key).Warn("Failed to write to cache")
+		}
+	}
+
+	return json.Unmarshal(b, value)
+}
+
+func (c *CachingCloudClient) invalidate(ctx context.Context, keys ...string) {
+	err := c.cache.Delete(ctx, keys...)
+	if err != nil {
+		c.logger.WithError(err).WithField("keys", keys).Warn("Failed to invalidate cache")
+	}
+}
+
+func installationCacheKey(installationID string, request *cloud.GetInstallationRequest) string {
+	if request == nil {
+		request = &cloud.GetInstallationRequest{}
+	}
+
+	return fmt.Sprintf("installation:%s:%t:%t", installationID, request.IncludeGroupConfig, request.IncludeGroupConfigOverrides)
+}
+
+func execCacheKey(clusterInstallationID, command string, subcommand []string) string {
+	return fmt.Sprintf("exec:%s:%s:%s", clusterInstallationID, command, strings.Join(subcommand, " "))
+}
+
+// listCacheKey derives the key of a list lookup from its kind and request.
+func listCacheKey(kind string, request interface{}) (string, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode request")
+	}
+
+	return fmt.Sprintf("%s:%s", kind, b), nil
+}
+
+// GetInstallation fetches the specified installation, preferring the cache.
+func (c *CachingCloudClient) GetInstallation(ctx context.Context, installationID string, request *cloud.GetInstallationRequest) (*cloud.InstallationDTO, error) {
+	var installation *cloud.InstallationDTO
+	err := c.cached(ctx, installationCacheKey(installationID, request), c.ttls.Get, &installation, func() (interface{}, error) {
+		return c.client.GetInstallation(ctx, installationID, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return installation, nil
+}
+
+// GetInstallations fetches the list of installations, preferring the cache.
+func (c *CachingCloudClient) GetInstallations(ctx context.Context, request *cloud.GetInstallationsRequest) ([]*cloud.InstallationDTO, error) {
+	key, err := listCacheKey("installations", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var installations []*cloud.InstallationDTO
+	err = c.cached(ctx, key, c.ttls.List, &installations, func() (interface{}, error) {
+		return c.client.GetInstallations(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return installations, nil
+}
+
+// UpdateInstallation updates an installation and invalidates its cached values.
+func (c *CachingCloudClient) UpdateInstallation(ctx context.Context, installationID string, request *cloud.PatchInstallationRequest) (*cloud.InstallationDTO, error) {
+	installation, err := c.client.UpdateInstallation(ctx, installationID, request)
+	c.invalidateInstallation(ctx, installationID)
+
+	return installation, err
+}
+
+// HibernateInstallation hibernates an installation and invalidates its cached values.
+func (c *CachingCloudClient) HibernateInstallation(ctx context.Context, installationID string) (*cloud.InstallationDTO, error) {
+	installation, err := c.client.HibernateInstallation(ctx, installationID)
+	c.invalidateInstallation(ctx, installationID)
+
+	return installation, err
+}
+
+// WakeupInstallation wakes an installation and invalidates its cached values.
+func (c *CachingCloudClient) WakeupInstallation(ctx context.Context, installationID string) (*cloud.InstallationDTO, error) {
+	installation, err := c.client.WakeupInstallation(ctx, installationID)
+	c.invalidateInstallation(ctx, installationID)
+
+	return installation, err
+}
+
+// DeleteInstallation requests the deletion of an installation and invalidates its cached values.
+func (c *CachingCloudClient) DeleteInstallation(ctx context.Context, installationID string) error {
+	err := c.client.DeleteInstallation(ctx, installationID)
+	c.invalidateInstallation(ctx, installationID)
+
+	return err
+}
+
+// invalidateInstallation invalidates every cached variant of the given installation.
+func (c *CachingCloudClient) invalidateInstallation(ctx context.Context, installationID string) {
+	keys := []string{}
+	for _, includeGroupConfig := range []bool{false, true} {
+		for _, includeGroupConfigOverrides := range []bool{false, true} {
+			keys = append(keys, installationCacheKey(installationID, &cloud.GetInstallationRequest{
+				IncludeGroupConfig:          includeGroupConfig,
+				IncludeGroupConfigOverrides: includeGroupConfigOverrides,
+			}))
+		}
+	}
+	c.invalidate(ctx, keys...)
+}
+
+// GetCluster fetches the specified cluster, preferring the cache.
+func (c *CachingCloudClient) GetCluster(ctx context.Context, clusterID string) (*cloud.ClusterDTO, error) {
+	var cluster *cloud.ClusterDTO
+	err := c.cached(ctx, fmt.Sprintf("cluster:%s", clusterID), c.ttls.Get, &cluster, func() (interface{}, error) {
+		return c.client.GetCluster(ctx, clusterID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cluster, nil
+}
+
+// GetClusters fetches the list of clusters, preferring the cache.
+func (c *CachingCloudClient) GetClusters(ctx context.Context, request *cloud.GetClustersRequest) ([]*cloud.ClusterDTO, error) {
+	key, err := listCacheKey("clusters", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []*cloud.ClusterDTO
+	err = c.cached(ctx, key, c.ttls.List, &clusters, func() (interface{}, error) {
+		return c.client.GetClusters(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// GetClusterInstallations fetches the list of cluster installations, preferring the cache.
+func (c *CachingCloudClient) GetClusterInstallations(ctx context.Context, request *cloud.GetClusterInstallationsRequest) ([]*cloud.ClusterInstallation, error) {
+	key, err := listCacheKey("cluster_installations", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusterInstallations []*cloud.ClusterInstallation
+	err = c.cached(ctx, key, c.ttls.List, &clusterInstallations, func() (interface{}, error) {
+		return c.client.GetClusterInstallations(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterInstallations, nil
+}
+
+// readOnlyMmctlCommands are the mmctl commands known not to change the
+// config of a cluster installation.
+var readOnlyMmctlCommands = [][]string{
+	{"config", "show"},
+	{"config", "get"},
+	{"plugin", "list"},
+	{"system", "status"},
+	{"system", "version"},
+	{"user", "list"},
+	{"user", "search"},
+	{"team", "list"},
+	{"team", "search"},
+	{"channel", "list"},
+	{"channel", "search"},
+	{"logs"},
+}
+
+// isReadOnlyCommand reports whether the command is known not to change the
+// config of a cluster installation.
+func isReadOnlyCommand(command string, subcommand []string) bool {
+	if command != "mmctl" {
+		return false
+	}
+
+	for _, readOnly := range readOnlyMmctlCommands {
+		if len(subcommand) >= len(readOnly) && strings.Join(subcommand[:len(readOnly)], " ") == strings.Join(readOnly, " ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExecClusterInstallationCLI runs a command against a cluster installation.
+// Only fetching the config and listing the plugins are cached; any other
+// command that may change the cluster installation invalidates both.
+func (c *CachingCloudClient) ExecClusterInstallationCLI(ctx context.Context, clusterInstallationID, command string, subcommand []string) ([]byte, error) {
+	configKey := execCacheKey(clusterInstallationID, "mmctl", configShowSubcommand)
+	pluginsKey := execCacheKey(clusterInstallationID, "mmctl", pluginListSubcommand)
+
+	key := execCacheKey(clusterInstallationID, command, subcommand)
+	var ttl time.Duration
+	switch key {
+	case configKey:
+		ttl = c.ttls.Config
+	case pluginsKey:
+		ttl = c.ttls.Plugins
+	default:
+		output, err := c.client.ExecClusterInstallationCLI(ctx, clusterInstallationID, command, subcommand)
+		if !isReadOnlyCommand(command, subcommand) {
+			c.invalidate(ctx, configKey, pluginsKey)
+		}
+		return output, err
+	}
+
+	var output []byte
+	err := c.cached(ctx, key, ttl, &output, func() (interface{}, error) {
+		return c.client.ExecClusterInstallationCLI(ctx, clusterInstallationID, command, subcommand)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// GetGroup fetches the specified group, preferring the cache.
+func (c *CachingCloudClient) GetGroup(ctx context.Context, groupID string) (*cloud.Group, error) {
+	var group *cloud.Group
+	err := c.cached(ctx, fmt.Sprintf("group:%s", groupID), c.ttls.Get, &group, func() (interface{}, error) {
+		return c.client.GetGroup(ctx, groupID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// GetGroups fetches the list of groups, preferring the cache.
+func (c *CachingCloudClient) GetGroups(ctx context.Context, request *cloud.GetGroupsRequest) ([]*cloud.Group, error) {
+	key, err := listCacheKey("groups", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*cloud.Group
+	err = c.cached(ctx, key, c.ttls.List, &groups, func() (interface{}, error) {
+		return c.client.GetGroups(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
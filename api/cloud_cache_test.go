@@ -0,0 +1,240 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+	"github.com/mattermost/pillar/utils"
+)
+
+func TestCachingCloudClient(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	now := time.Unix(1600000000, 0)
+	client := NewCachingCloudClient(mockCloudClient, NewMemoryCache(), CacheTTLs{Get: time.Minute, List: time.Minute, Config: time.Minute, Plugins: time.Minute}, logger)
+	client.now = func() time.Time { return now }
+
+	t.Run("lookups are cached", func(t *testing.T) {
+		mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid", Version: "5.31.0"}}
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+
+		installation, err := client.GetInstallation(ctx, "installationid", &cloud.GetInstallationRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, mockInstallation, installation)
+
+		usageCtx, usage := withCacheUsage(ctx)
+		installation, err = client.GetInstallation(usageCtx, "installationid", &cloud.GetInstallationRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, mockInstallation, installation)
+		assert.Equal(t, int64(1600000000000), usage.CachedAt())
+	})
+
+	t.Run("refresh bypasses the cache", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetGroup(gomock.Any(), gomock.Eq("groupid")).Times(2).Return(&cloud.Group{ID: "groupid"}, nil)
+
+		_, err := client.GetGroup(ctx, "groupid")
+		require.NoError(t, err)
+
+		usageCtx, usage := withCacheUsage(withCacheRefresh(ctx))
+		group, err := client.GetGroup(usageCtx, "groupid")
+		require.NoError(t, err)
+		assert.Equal(t, "groupid", group.ID)
+		assert.Zero(t, usage.CachedAt())
+	})
+
+	t.Run("missing values are not cached", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetCluster(gomock.Any(), gomock.Eq("missing")).Times(2).Return(nil, nil)
+
+		for i := 0; i < 2; i++ {
+			cluster, err := client.GetCluster(ctx, "missing")
+			require.NoError(t, err)
+			assert.Nil(t, cluster)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetGroups(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+		mockCloudClient.EXPECT().GetGroups(gomock.Any(), gomock.Any()).Times(1).Return([]*cloud.Group{{ID: "groupid"}}, nil)
+
+		_, err := client.GetGroups(ctx, &cloud.GetGroupsRequest{PerPage: 10})
+		assert.EqualError(t, err, "some error")
+
+		groups, err := client.GetGroups(ctx, &cloud.GetGroupsRequest{PerPage: 10})
+		require.NoError(t, err)
+		assert.Len(t, groups, 1)
+	})
+
+	t.Run("lists are cached by request", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(2).Return([]*cloud.ClusterInstallation{{ID: "clusterinstallationid"}}, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetClusterInstallations(ctx, &cloud.GetClusterInstallationsRequest{InstallationID: "first"})
+			require.NoError(t, err)
+			_, err = client.GetClusterInstallations(ctx, &cloud.GetClusterInstallationsRequest{InstallationID: "second"})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("updates invalidate the installation", func(t *testing.T) {
+		mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "updatedid", Version: "5.31.0"}}
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("updatedid"), gomock.Any()).Times(2).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().UpdateInstallation(gomock.Any(), gomock.Eq("updatedid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+
+		_, err := client.GetInstallation(ctx, "updatedid", &cloud.GetInstallationRequest{IncludeGroupConfig: true})
+		require.NoError(t, err)
+
+		_, err = client.UpdateInstallation(ctx, "updatedid", &cloud.PatchInstallationRequest{Version: utils.NewString("5.32.0")})
+		require.NoError(t, err)
+
+		_, err = client.GetInstallation(ctx, "updatedid", &cloud.GetInstallationRequest{IncludeGroupConfig: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("hibernation invalidates the installation", func(t *testing.T) {
+		mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "hibernatedid", State: cloud.InstallationStateStable}}
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("hibernatedid"), gomock.Any()).Times(2).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().HibernateInstallation(gomock.Any(), gomock.Eq("hibernatedid")).Times(1).Return(mockInstallation, nil)
+
+		_, err := client.GetInstallation(ctx, "hibernatedid", &cloud.GetInstallationRequest{})
+		require.NoError(t, err)
+
+		_, err = client.HibernateInstallation(ctx, "hibernatedid")
+		require.NoError(t, err)
+
+		_, err = client.GetInstallation(ctx, "hibernatedid", &cloud.GetInstallationRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("config is cached until changed", func(t *testing.T) {
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(configShowSubcommand)).Times(2).Return([]byte(`{"ServiceSettings":{}}`), nil)
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"config", "set", "--local", "--", "ServiceSettings.SiteURL", "https://example.com"})).Times(1).Return([]byte{}, nil)
+
+		for i := 0; i < 2; i++ {
+			output, err := client.ExecClusterInstallationCLI(ctx, "clusterinstallationid", "mmctl", configShowSubcommand)
+			require.NoError(t, err)
+			assert.Equal(t, `{"ServiceSettings":{}}`, string(output))
+		}
+
+		// Read-only commands leave the cached config in place.
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(pluginListSubcommand)).Times(1).Return([]byte(`{}`), nil)
+		_, err := client.ExecClusterInstallationCLI(ctx, "clusterinstallationid", "mmctl", pluginListSubcommand)
+		require.NoError(t, err)
+		_, err = client.ExecClusterInstallationCLI(ctx, "clusterinstallationid", "mmctl", configShowSubcommand)
+		require.NoError(t, err)
+
+		_, err = client.ExecClusterInstallationCLI(ctx, "clusterinstallationid", "mmctl", []string{"config", "set", "--local", "--", "ServiceSettings.SiteURL", "https://example.com"})
+		require.NoError(t, err)
+
+		_, err = client.ExecClusterInstallationCLI(ctx, "clusterinstallationid", "mmctl", configShowSubcommand)
+		require.NoError(t, err)
+	})
+
+	t.Run("plugins are cached until changed", func(t *testing.T) {
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("pluginsid"), gomock.Eq("mmctl"), gomock.Eq(pluginListSubcommand)).Times(2).Return([]byte(`{"active":[],"inactive":[]}`), nil)
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("pluginsid"), gomock.Eq("mmctl"), gomock.Eq([]string{"plugin", "enable", "--local", "com.mattermost.demo"})).Times(1).Return([]byte{}, nil)
+
+		for i := 0; i < 2; i++ {
+			output, err := client.ExecClusterInstallationCLI(ctx, "pluginsid", "mmctl", pluginListSubcommand)
+			require.NoError(t, err)
+			assert.Equal(t, `{"active":[],"inactive":[]}`, string(output))
+		}
+
+		_, err := client.ExecClusterInstallationCLI(ctx, "pluginsid", "mmctl", []string{"plugin", "enable", "--local", "com.mattermost.demo"})
+		require.NoError(t, err)
+
+		_, err = client.ExecClusterInstallationCLI(ctx, "pluginsid", "mmctl", pluginListSubcommand)
+		require.NoError(t, err)
+	})
+
+	t.Run("zero ttl disables caching", func(t *testing.T) {
+		uncachedClient := NewCachingCloudClient(mockCloudClient, NewMemoryCache(), CacheTTLs{}, logger)
+		mockCloudClient.EXPECT().GetClusters(gomock.Any(), gomock.Any()).Times(2).Return([]*cloud.ClusterDTO{}, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := uncachedClient.GetClusters(ctx, &cloud.GetClustersRequest{})
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestCachedRequests(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: NewCachingCloudClient(mockCloudClient, NewMemoryCache(), DefaultCacheTTLs, logger),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("cache age header", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetGroup(gomock.Any(), gomock.Eq("groupid")).Times(1).Return(&cloud.Group{ID: "groupid"}, nil)
+
+		resp, err := http.Get(ts.URL + "/api/v1/groups/groupid")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderCacheAge))
+
+		resp, err = http.Get(ts.URL + "/api/v1/groups/groupid")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		age, err := strconv.Atoi(resp.Header.Get(HeaderCacheAge))
+		require.NoError(t, err)
+		assert.True(t, age >= 0)
+	})
+
+	t.Run("invalid refresh", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/groups/groupid?refresh=maybe")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("get workspace", func(t *testing.T) {
+		client := NewClient(ts.URL)
+		client.SetJustification("customer requested help", "")
+
+		mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(2).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(2).Return([]*cloud.ClusterInstallation{{ID: "clusterinstallationid"}}, nil)
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Any()).Times(2).Return([]byte(`{"ServiceSettings":{}}`), nil)
+
+		workspace, err := client.GetWorkspace("installationid")
+		require.NoError(t, err)
+		assert.Zero(t, workspace.CachedAt)
+
+		workspace, err = client.GetWorkspace("installationid")
+		require.NoError(t, err)
+		assert.NotZero(t, workspace.CachedAt)
+		assert.NotNil(t, workspace.Config.Settings)
+
+		client.SetRefresh(true)
+		workspace, err = client.GetWorkspace("installationid")
+		require.NoError(t, err)
+		assert.Zero(t, workspace.CachedAt)
+	})
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+func TestProvisionerClient(t *testing.T) {
+	release := make(chan struct{})
+	hung := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/installation/installationid", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}})
+	}).Methods("GET")
+	router.HandleFunc("/api/installation/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	router.HandleFunc("/api/installation/installationid/mattermost", func(w http.ResponseWriter, r *http.Request) {
+		request := &cloud.PatchInstallationRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(request))
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid", Version: *request.Version}})
+	}).Methods("PUT")
+	router.HandleFunc("/api/installation/installationid/hibernate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid", State: cloud.InstallationStateHibernationRequested}})
+	}).Methods("POST")
+	router.HandleFunc("/api/installation/installationid", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}).Methods("DELETE")
+	router.HandleFunc("/api/groups", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		json.NewEncoder(w).Encode([]*cloud.Group{{ID: "groupid"}})
+	}).Methods("GET")
+	router.HandleFunc("/api/cluster_installation/clusterinstallationid/exec/mmctl", func(w http.ResponseWriter, r *http.Request) {
+		var args []string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&args))
+		assert.Equal(t, []string{"config", "show", "--local"}, args)
+		w.Write([]byte(`{"ServiceSettings":{}}`))
+	}).Methods("POST")
+	router.HandleFunc("/api/cluster_installation/hung/exec/mmctl", hung).Methods("POST")
+	router.HandleFunc("/api/group/hung", hung).Methods("GET")
+	cancelled := make(chan struct{}, 1)
+	router.HandleFunc("/api/installation/hung/mattermost", func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body was read.
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		case <-release:
+		}
+	}).Methods("PUT")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	defer close(release)
+
+	client := NewProvisionerClient(ts.URL, CloudClientTimeouts{Get: 5 * time.Second, Exec: 50 * time.Millisecond})
+
+	t.Run("get installation", func(t *testing.T) {
+		installation, err := client.GetInstallation(context.Background(), "installationid", &cloud.GetInstallationRequest{IncludeGroupConfig: true})
+		require.NoError(t, err)
+		assert.Equal(t, "installationid", installation.ID)
+	})
+
+	t.Run("installation not found", func(t *testing.T) {
+		installation, err := client.GetInstallation(context.Background(), "missing", &cloud.GetInstallationRequest{})
+		require.NoError(t, err)
+		assert.Nil(t, installation)
+	})
+
+	t.Run("update installation", func(t *testing.T) {
+		version := "5.31.0"
+		installation, err := client.UpdateInstallation(context.Background(), "installationid", &cloud.PatchInstallationRequest{Version: &version})
+		require.NoError(t, err)
+		assert.Equal(t, "5.31.0", installation.Version)
+	})
+
+	t.Run("hibernate installation", func(t *testing.T) {
+		installation, err := client.HibernateInstallation(context.Background(), "installationid")
+		require.NoError(t, err)
+		assert.Equal(t, cloud.InstallationStateHibernationRequested, installation.State)
+	})
+
+	t.Run("wake up missing installation", func(t *testing.T) {
+		installation, err := client.WakeupInstallation(context.Background(), "missing")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, installation)
+	})
+
+	t.Run("delete installation", func(t *testing.T) {
+		err := client.DeleteInstallation(context.Background(), "installationid")
+		require.NoError(t, err)
+	})
+
+	t.Run("get groups", func(t *testing.T) {
+		groups, err := client.GetGroups(context.Background(), &cloud.GetGroupsRequest{Page: 2, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "groupid", groups[0].ID)
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		clusters, err := client.GetClusters(context.Background(), &cloud.GetClustersRequest{})
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, clusters)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		output, err := client.ExecClusterInstallationCLI(context.Background(), "clusterinstallationid", "mmctl", []string{"config", "show", "--local"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"ServiceSettings":{}}`, string(output))
+	})
+
+	t.Run("exec times out", func(t *testing.T) {
+		start := time.Now()
+		_, err := client.ExecClusterInstallationCLI(context.Background(), "hung", "mmctl", []string{"config", "show", "--local"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	})
+
+	t.Run("update times out", func(t *testing.T) {
+		client := NewProvisionerClient(ts.URL, CloudClientTimeouts{Update: 50 * time.Millisecond})
+		version := "5.31.0"
+		installation, err := client.UpdateInstallation(context.Background(), "hung", &cloud.PatchInstallationRequest{Version: &version})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the change may still be applied")
+		assert.Nil(t, installation)
+
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "request was not cancelled")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		group, err := client.GetGroup(ctx, "hung")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), context.Canceled.Error())
+		assert.Nil(t, group)
+	})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// clusterWorkspaceFetchConcurrency limits the number of workspaces fetched
+// from the provisioner at once when listing the workspaces of a cluster.
+const clusterWorkspaceFetchConcurrency = 10
+
+// initCluster registers cluster endpoints on the given router.
+func initCluster(apiRouter *mux.Router, context *Context) {
+	clustersRouter := apiRouter.PathPrefix("/clusters").Subrouter()
+	clustersRouter.Handle("", newAPIHandler(context, handleListClusters, PermissionClusterRead)).Methods("GET")
+	clustersRouter.Handle("/{cluster}/workspaces", newAPIHandler(context, handleListClusterWorkspaces, PermissionClusterRead)).Methods("GET")
+}
+
+// Cluster is a respresentation of a Kubernetes cluster hosting workspaces.
+type Cluster struct {
+	ID                 string   `json:"id"`
+	State              string   `json:"state"`
+	Provider           string   `json:"provider"`
+	Provisioner        string   `json:"provisioner"`
+	Version            string   `json:"version"`
+	AllowInstallations bool     `json:"allow_installations"`
+	Annotations        []string `json:"annotations"`
+	CreateAt           int64    `json:"create_at"`
+	DeleteAt           int64    `json:"delete_at"`
+}
+
+// ClusterInstallation is the deployment of a workspace on a single cluster.
+type ClusterInstallation struct {
+	ID          string `json:"id"`
+	ClusterID   string `json:"cluster_id"`
+	WorkspaceID string `json:"workspace_id"`
+	Namespace   string `json:"namespace"`
+	State       string `json:"state"`
+	CreateAt    int64  `json:"create_at"`
+	DeleteAt    int64  `json:"delete_at"`
+}
+
+// ClusterWorkspace is a workspace hosted on a cluster. Workspace is nil if the
+// workspace of the cluster installation no longer exists.
+type ClusterWorkspace struct {
+	ClusterInstallation *ClusterInstallation `json:"cluster_installation"`
+	Workspace           *Workspace           `json:"workspace"`
+}
+
+// handleListClusters responds to GET /api/v1/clusters, listing clusters.
+func handleListClusters(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePaging(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	includeDeleted, err := parseBool(r.URL.Query(), "include_deleted", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	clusters, err := c.CloudClient.GetClusters(r.Context(), &cloud.GetClustersRequest{
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	resp := convertCloudClustersToClusters(clusters)
+	if resp == nil {
+		resp = []*Cluster{}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleListClusterWorkspaces responds to GET /api/v1/clusters/{id}/workspaces,
+// listing the workspaces hosted on a cluster.
+func handleListClusterWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clusterID := vars["cluster"]
+	c.Logger = c.Logger.WithField("cluster", clusterID)
+
+	page, perPage, err := parsePaging(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	includeDeleted, err := parseBool(r.URL.Query(), "include_deleted", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	cluster, err := c.CloudClient.GetCluster(r.Context(), clusterID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if cluster == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	clusterInstallations, err := c.CloudClient.GetClusterInstallations(r.Context(), &cloud.GetClusterInstallationsRequest{
+		ClusterID:      clusterID,
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	resp, err := getClusterWorkspaces(r.Context(), c.CloudClient, c.redactor(), clusterInstallations)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// getClusterWorkspaces fetches the workspace of each of the given cluster
+// installations, preserving their order.
+func getClusterWorkspaces(ctx context.Context, client CloudClient, redactor *Redactor, clusterInstallations []*cloud.ClusterInstallation) ([]*ClusterWorkspace, error) {
+	clusterWorkspaces := make([]*ClusterWorkspace, len(clusterInstallations))
+	errs := make([]error, len(clusterInstallations))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, clusterWorkspaceFetchConcurrency)
+	for i, clusterInstallation := range clusterInstallations {
+		wg.Add(1)
+		go func(i int, clusterInstallation *cloud.ClusterInstallation) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			installation, err := client.GetInstallation(ctx, clusterInstallation.InstallationID, &cloud.GetInstallationRequest{})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			clusterWorkspaces[i] = &ClusterWorkspace{
+				ClusterInstallation: convertCloudClusterInstallationToClusterInstallation(clusterInstallation),
+				Workspace:           convertInstallationToWorkspace(installation, redactor),
+			}
+		}(i, clusterInstallation)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clusterWorkspaces, nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestCluster(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	t.Run("list clusters", func(t *testing.T) {
+		t.Run("success", func(t *testing.T) {
+			mockClusters := []*cloud.ClusterDTO{{Cluster: &cloud.Cluster{ID: "clusterid", State: cloud.ClusterStateStable}}}
+			mockCloudClient.EXPECT().
+				GetClusters(gomock.Any(), gomock.Eq(&cloud.GetClustersRequest{Page: 0, PerPage: 100})).
+				Times(1).
+				Return(mockClusters, nil)
+
+			clusters, err := client.ListClusters(&cloud.GetClustersRequest{})
+			require.NoError(t, err)
+			require.Len(t, clusters, 1)
+			assert.Equal(t, "clusterid", clusters[0].ID)
+			assert.Equal(t, cloud.ClusterStateStable, clusters[0].State)
+		})
+
+		t.Run("error getting clusters", func(t *testing.T) {
+			mockCloudClient.EXPECT().GetClusters(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+			clusters, err := client.ListClusters(&cloud.GetClustersRequest{})
+			assert.Error(t, err)
+			assert.Nil(t, clusters)
+		})
+	})
+
+	t.Run("list cluster workspaces", func(t *testing.T) {
+		t.Run("success", func(t *testing.T) {
+			mockCloudClient.EXPECT().GetCluster(gomock.Any(), gomock.Eq("clusterid")).Times(1).Return(&cloud.ClusterDTO{Cluster: &cloud.Cluster{ID: "clusterid"}}, nil)
+			mockClusterInstallations := []*cloud.ClusterInstallation{
+				{ID: "clusterinstallationid1", ClusterID: "clusterid", InstallationID: "installationid1", Namespace: "installationid1", State: cloud.ClusterInstallationStateStable},
+				{ID: "clusterinstallationid2", ClusterID: "clusterid", InstallationID: "installationid2", Namespace: "installationid2", State: cloud.ClusterInstallationStateStable},
+			}
+			mockCloudClient.EXPECT().
+				GetClusterInstallations(gomock.Any(), gomock.Eq(&cloud.GetClusterInstallationsRequest{ClusterID: "clusterid", Page: 2, PerPage: 2, IncludeDeleted: true})).
+				Times(1).
+				Return(mockClusterInstallations, nil)
+			mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid1"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid1", DNS: "customer1.cloud.mattermost.com"}}, nil)
+			mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid2"), gomock.Any()).Times(1).Return(nil, nil)
+
+			clusterWorkspaces, err := client.ListClusterWorkspaces("clusterid", &cloud.GetClusterInstallationsRequest{Page: 2, PerPage: 2, IncludeDeleted: true})
+			require.NoError(t, err)
+			require.Len(t, clusterWorkspaces, 2)
+			assert.Equal(t, "clusterinstallationid1", clusterWorkspaces[0].ClusterInstallation.ID)
+			require.NotNil(t, clusterWorkspaces[0].Workspace)
+			assert.Equal(t, "customer1.cloud.mattermost.com", clusterWorkspaces[0].Workspace.DNS)
+			assert.Equal(t, "clusterinstallationid2", clusterWorkspaces[1].ClusterInstallation.ID)
+			assert.Nil(t, clusterWorkspaces[1].Workspace)
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			mockCloudClient.EXPECT().GetCluster(gomock.Any(), gomock.Eq("clusterid")).Times(1).Return(nil, nil)
+
+			clusterWorkspaces, err := client.ListClusterWorkspaces("clusterid", &cloud.GetClusterInstallationsRequest{})
+			assert.EqualError(t, err, "failed with status code 404")
+			assert.Nil(t, clusterWorkspaces)
+		})
+
+		t.Run("error getting workspace", func(t *testing.T) {
+			mockCloudClient.EXPECT().GetCluster(gomock.Any(), gomock.Eq("clusterid")).Times(1).Return(&cloud.ClusterDTO{Cluster: &cloud.Cluster{ID: "clusterid"}}, nil)
+			mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return([]*cloud.ClusterInstallation{{ID: "clusterinstallationid", InstallationID: "installationid"}}, nil)
+			mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+			clusterWorkspaces, err := client.ListClusterWorkspaces("clusterid", &cloud.GetClusterInstallationsRequest{})
+			assert.EqualError(t, err, "failed with status code 500: some error")
+			assert.Nil(t, clusterWorkspaces)
+		})
+	})
+}
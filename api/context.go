@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -13,21 +14,46 @@ import (
 //
 // It is cloned before each request, allowing per-request changes such as logger annotations.
 type Context struct {
-	RequestID   string
-	Logger      logrus.FieldLogger
-	CloudClient CloudClient
+	RequestID             string
+	Logger                logrus.FieldLogger
+	CloudClient           CloudClient
+	Redactor              *Redactor
+	AllowUnredactedConfig bool
+	Authenticator         Authenticator
+	Authorizer            *Authorizer
+	AuditStore            AuditStore
+	WorkspaceIndex        WorkspaceIndex
+	TrustedProxies        []string
+	// WorkspaceHTTPClient is used to reach the servers of workspaces directly.
+	WorkspaceHTTPClient *http.Client
+	// ImageRegistry checks that the image tags workspaces are updated to
+	// exist. Only the syntax of versions is checked when it is nil.
+	ImageRegistry ImageRegistry
+	// PluginScanLimiter limits how many workspaces are asked for their
+	// plugins when searching for a plugin across workspaces. Searches are
+	// not limited when it is nil.
+	PluginScanLimiter *RateLimiter
+	Identity          *Identity
+	Justification     *Justification
 }
 
-// Compile-time check to ensure CloudClient is implemented by cloud.Client
-var _ CloudClient = &cloud.Client{}
-
 // CloudClient is an interface that defines the client for connecting to the cloud provisioner.
+//
+// Every call is bound to the given context, so that requests to the provisioner
+// are cancelled along with the API request that made them.
 type CloudClient interface {
-	GetInstallation(string, *cloud.GetInstallationRequest) (*cloud.InstallationDTO, error)
-	GetInstallations(*cloud.GetInstallationsRequest) ([]*cloud.InstallationDTO, error)
-	GetClusterInstallations(*cloud.GetClusterInstallationsRequest) ([]*cloud.ClusterInstallation, error)
-	ExecClusterInstallationCLI(string, string, []string) ([]byte, error)
-	GetGroup(string) (*cloud.Group, error)
+	GetInstallation(context.Context, string, *cloud.GetInstallationRequest) (*cloud.InstallationDTO, error)
+	GetInstallations(context.Context, *cloud.GetInstallationsRequest) ([]*cloud.InstallationDTO, error)
+	UpdateInstallation(context.Context, string, *cloud.PatchInstallationRequest) (*cloud.InstallationDTO, error)
+	HibernateInstallation(context.Context, string) (*cloud.InstallationDTO, error)
+	WakeupInstallation(context.Context, string) (*cloud.InstallationDTO, error)
+	DeleteInstallation(context.Context, string) error
+	GetCluster(context.Context, string) (*cloud.ClusterDTO, error)
+	GetClusters(context.Context, *cloud.GetClustersRequest) ([]*cloud.ClusterDTO, error)
+	GetClusterInstallations(context.Context, *cloud.GetClusterInstallationsRequest) ([]*cloud.ClusterInstallation, error)
+	ExecClusterInstallationCLI(context.Context, string, string, []string) ([]byte, error)
+	GetGroup(context.Context, string) (*cloud.Group, error)
+	GetGroups(context.Context, *cloud.GetGroupsRequest) ([]*cloud.Group, error)
 }
 
 // Error represents an error response in the API
@@ -38,9 +64,52 @@ type Error struct {
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
 func (c *Context) Clone() *Context {
 	return &Context{
-		Logger:      c.Logger,
-		CloudClient: c.CloudClient,
+		Logger:                c.Logger,
+		CloudClient:           c.CloudClient,
+		Redactor:              c.Redactor,
+		AllowUnredactedConfig: c.AllowUnredactedConfig,
+		Authenticator:         c.Authenticator,
+		Authorizer:            c.Authorizer,
+		AuditStore:            c.AuditStore,
+		WorkspaceIndex:        c.WorkspaceIndex,
+		TrustedProxies:        c.TrustedProxies,
+		WorkspaceHTTPClient:   c.WorkspaceHTTPClient,
+		ImageRegistry:         c.ImageRegistry,
+		PluginScanLimiter:     c.PluginScanLimiter,
+	}
+}
+
+// can returns true if the identity making the request has the given
+// permission. Every permission is granted when no authorizer is configured.
+func (c *Context) can(permission Permission) bool {
+	if c.Authorizer == nil {
+		return true
+	}
+	if c.Identity == nil {
+		return false
 	}
+
+	return c.Authorizer.HasPermission(c.Identity.Roles, permission)
+}
+
+// redactor returns the configured redactor, falling back to the default
+// denylist so that configs are never returned unredacted by accident.
+func (c *Context) redactor() *Redactor {
+	if c.Redactor == nil {
+		return defaultRedactor
+	}
+
+	return c.Redactor
+}
+
+// workspaceHTTPClient returns the configured client for reaching workspaces,
+// falling back to a default client with a timeout.
+func (c *Context) workspaceHTTPClient() *http.Client {
+	if c.WorkspaceHTTPClient == nil {
+		return defaultWorkspaceHTTPClient
+	}
+
+	return c.WorkspaceHTTPClient
 }
 
 func (c *Context) writeAndLogErrorWithFields(w http.ResponseWriter, err error, logFields logrus.Fields) {
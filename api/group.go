@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// initGroup registers group endpoints on the given router.
+func initGroup(apiRouter *mux.Router, context *Context) {
+	groupsRouter := apiRouter.PathPrefix("/groups").Subrouter()
+	groupsRouter.Handle("", newAPIHandler(context, handleListGroups, PermissionGroupRead)).Methods("GET")
+	groupsRouter.Handle("/{group}", newAPIHandler(context, handleGetGroup, PermissionGroupRead)).Methods("GET")
+	groupsRouter.Handle("/{group}/workspaces", newAPIHandler(context, handleListGroupWorkspaces, PermissionGroupRead)).Methods("GET")
+}
+
+// Group is a respresentation of an installation group without certain sensitive fields
+// and data catered to be useful to the support team.
+type Group struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Image       string `json:"image"`
+	MaxRolling  int64  `json:"max_rolling"`
+	CreateAt    int64  `json:"create_at"`
+	DeleteAt    int64  `json:"delete_at"`
+}
+
+// GroupDetailed contains a group and the configuration it applies to its workspaces.
+type GroupDetailed struct {
+	*Group
+	MattermostEnv cloud.EnvVarMap `json:"mattermost_env"`
+	EnvRedacted   bool            `json:"env_redacted"`
+}
+
+// handleListGroups responds to GET /api/v1/groups, listing groups.
+func handleListGroups(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePaging(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	includeDeleted, err := parseBool(r.URL.Query(), "include_deleted", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	groups, err := c.CloudClient.GetGroups(r.Context(), &cloud.GetGroupsRequest{
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	resp := convertCloudGroupsToGroups(groups)
+	if resp == nil {
+		resp = []*Group{}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleGetGroup responds to GET /api/v1/groups/{id}, getting a group with the
+// configuration it applies to its workspaces. Sensitive env var values are redacted.
+func handleGetGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupID := vars["group"]
+	c.Logger = c.Logger.WithField("group", groupID)
+
+	group, err := c.CloudClient.GetGroup(r.Context(), groupID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if group == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	groupDetailed := &GroupDetailed{
+		Group:         convertCloudGroupToGroup(group),
+		MattermostEnv: c.redactor().RedactEnv(group.MattermostEnv),
+		EnvRedacted:   true,
+	}
+
+	b, err := json.Marshal(groupDetailed)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleListGroupWorkspaces responds to GET /api/v1/groups/{id}/workspaces,
+// listing the workspaces that belong to a group.
+func handleListGroupWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	groupID := vars["group"]
+	c.Logger = c.Logger.WithField("group", groupID)
+
+	page, perPage, err := parsePaging(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	includeDeleted, err := parseBool(r.URL.Query(), "include_deleted", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	group, err := c.CloudClient.GetGroup(r.Context(), groupID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if group == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	installations, err := c.CloudClient.GetInstallations(r.Context(), &cloud.GetInstallationsRequest{
+		GroupID:        groupID,
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	resp := convertInstallationsToWorkspaces(installations, c.redactor())
+	if resp == nil {
+		resp = []*Workspace{}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
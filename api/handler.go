@@ -5,7 +5,10 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/mattermost/pillar/utils"
@@ -16,9 +19,11 @@ type contextHandlerFunc func(c *Context, w http.ResponseWriter, r *http.Request)
 var _ http.Handler = contextHandler{}
 
 type contextHandler struct {
-	context  *Context
-	handler  contextHandlerFunc
-	isStatic bool
+	context              *Context
+	handler              contextHandlerFunc
+	permission           Permission
+	requireJustification bool
+	isStatic             bool
 }
 
 func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -29,12 +34,89 @@ func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"request": context.RequestID,
 	})
 
-	h.setDefaultHeaders(w, r)
-	h.handler(context, w, r)
+	h.setDefaultHeaders(w, r, context.RequestID)
+
+	if h.isStatic {
+		h.handler(context, w, r)
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	defer func() {
+		recordAudit(context, r, h.permission, recorder.statusCode)
+	}()
+
+	h.serveAPI(context, recorder, r)
+}
+
+// serveAPI authenticates and authorizes the request before passing it to the handler.
+func (h contextHandler) serveAPI(context *Context, w http.ResponseWriter, r *http.Request) {
+	// The justification is parsed up front so that it is logged and audited
+	// even when the request is rejected.
+	justification, justificationErr := parseJustification(w, r)
+	if justification != nil {
+		context.Justification = justification
+		context.Logger = context.Logger.WithFields(logrus.Fields{
+			"justification": justification.Reason,
+			"ticket":        justification.TicketID,
+		})
+	}
+
+	if context.Authenticator != nil {
+		identity, err := context.Authenticator.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			context.writeAndLogError(w, errors.Wrap(err, "failed to authenticate request"))
+			return
+		}
+
+		context.Identity = identity
+		context.Logger = context.Logger.WithField("user", identity.Name())
+	}
+
+	if context.Authorizer != nil {
+		if context.Identity != nil {
+			context.Identity.Roles = context.Authorizer.RolesFor(context.Identity)
+		}
+
+		if !context.can(h.permission) {
+			w.WriteHeader(http.StatusForbidden)
+			context.writeAndLogError(w, errors.Errorf("missing permission %s", h.permission))
+			return
+		}
+	}
+
+	if justificationErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		context.writeAndLogError(w, errors.Wrap(justificationErr, "invalid justification"))
+		return
+	}
+	if h.requireJustification && context.Justification == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		context.writeAndLogError(w, errors.Errorf("a justification must be provided with the %s header", HeaderJustification))
+		return
+	}
+
+	refresh, err := parseBool(r.URL.Query(), "refresh", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		context.writeAndLogError(w, err)
+		return
+	}
+
+	// Changes always start from fresh provisioner data.
+	ctx := r.Context()
+	if refresh || r.Method != http.MethodGet {
+		ctx = withCacheRefresh(ctx)
+	}
+	ctx, usage := withCacheUsage(ctx)
+
+	h.handler(context, &cacheAgeWriter{ResponseWriter: w, usage: usage}, r.WithContext(ctx))
 }
 
-func (h contextHandler) setDefaultHeaders(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("X-Request-ID", h.context.RequestID)
+func (h contextHandler) setDefaultHeaders(w http.ResponseWriter, r *http.Request, requestID string) {
+	w.Header().Set("X-Request-ID", requestID)
 
 	if h.isStatic {
 		// Instruct the browser not to display us in an iframe unless is the same origin for anti-clickjacking
@@ -59,9 +141,77 @@ func newStaticHandler(context *Context, handler contextHandlerFunc) *contextHand
 	}
 }
 
-func newAPIHandler(context *Context, handler contextHandlerFunc) *contextHandler {
+// newAPIHandler creates a handler for an API endpoint that may only be used by
+// callers with the given permission.
+func newAPIHandler(context *Context, handler contextHandlerFunc, permission Permission) *contextHandler {
+	return &contextHandler{
+		context:    context,
+		handler:    handler,
+		permission: permission,
+	}
+}
+
+// newJustifiedAPIHandler creates a handler for a sensitive API endpoint that
+// additionally requires callers to justify each request.
+func newJustifiedAPIHandler(context *Context, handler contextHandlerFunc, permission Permission) *contextHandler {
 	return &contextHandler{
-		context: context,
-		handler: handler,
+		context:              context,
+		handler:              handler,
+		permission:           permission,
+		requireJustification: true,
+	}
+}
+
+// statusRecorder is an http.ResponseWriter that remembers the status code written to it.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush sends buffered data to the client, so that responses such as support
+// packets can be streamed through the status recorder.
+func (w *statusRecorder) Flush() {
+	flush(w.ResponseWriter)
+}
+
+// flush flushes the given writer if it supports flushing.
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// HeaderCacheAge is the response header holding the age in seconds of the
+// oldest cached provisioner data used to answer the request. It is omitted
+// when no cached data was used.
+const HeaderCacheAge = "X-Pillar-Cache-Age"
+
+// cacheAgeWriter is an http.ResponseWriter that sets the cache age header
+// before the response is written.
+type cacheAgeWriter struct {
+	http.ResponseWriter
+	usage *cacheUsage
+}
+
+func (w *cacheAgeWriter) WriteHeader(statusCode int) {
+	if cachedAt := w.usage.CachedAt(); cachedAt != 0 {
+		age := time.Since(time.Unix(0, cachedAt*int64(time.Millisecond)))
+		w.Header().Set(HeaderCacheAge, strconv.FormatInt(int64(age/time.Second), 10))
 	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush passes flushes on to the wrapped writer, which cacheAgeWriter would
+// otherwise hide from handlers streaming their response.
+func (w *cacheAgeWriter) Flush() {
+	flush(w.ResponseWriter)
 }
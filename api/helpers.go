@@ -3,6 +3,10 @@ package api
 import (
 	"encoding/json"
 	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 func decodeJSON(obj interface{}, body io.Reader) error {
@@ -14,3 +18,75 @@ func decodeJSON(obj interface{}, body io.Reader) error {
 
 	return nil
 }
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// parseBool parses the named boolean query parameter, returning the default
+// value if it is absent.
+func parseBool(u url.Values, name string, defaultValue bool) (bool, error) {
+	valueStr := u.Get(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to parse %s as boolean", name)
+	}
+
+	return value, nil
+}
+
+// parseInt64 parses the named integer query parameter, returning the default
+// value if it is absent.
+func parseInt64(u url.Values, name string, defaultValue int64) (int64, error) {
+	valueStr := u.Get(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s as integer", name)
+	}
+
+	return value, nil
+}
+
+// parsePaging parses the page and per_page query parameters.
+func parsePaging(u url.Values) (int, int, error) {
+	page, err := parseInt64(u, "page", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	if page < 0 {
+		return 0, 0, errors.New("page must not be negative")
+	}
+
+	perPage, err := parseInt64(u, "per_page", 100)
+	if err != nil {
+		return 0, 0, err
+	}
+	if perPage < 1 || perPage > 1000 {
+		return 0, 0, errors.New("per_page must be between 1 and 1000")
+	}
+
+	return int(page), int(perPage), nil
+}
+
+// contains returns true if the list contains the given value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderJustification carries the reason given for a request.
+	HeaderJustification = "X-Pillar-Justification"
+	// HeaderTicketID carries the support ticket a request is made for.
+	HeaderTicketID = "X-Pillar-Ticket-ID"
+
+	maxJustificationReasonLength   = 1024
+	maxJustificationTicketIDLength = 128
+
+	// maxRequestBodySize limits the size of request bodies, which are read in
+	// full to find the justification.
+	maxRequestBodySize = 1 << 20
+)
+
+// Justification is the reason given for accessing or changing a customer's
+// workspace. It is provided through the X-Pillar-Justification and
+// X-Pillar-Ticket-ID headers, or as the justification field of a JSON request
+// body.
+type Justification struct {
+	Reason   string `json:"reason"`
+	TicketID string `json:"ticket_id,omitempty"`
+}
+
+// Validate validates the values of a justification.
+func (j *Justification) Validate() error {
+	if strings.TrimSpace(j.Reason) == "" {
+		return errors.New("justification reason must not be empty")
+	}
+	if len(j.Reason) > maxJustificationReasonLength {
+		return errors.Errorf("justification reason must not be longer than %d characters", maxJustificationReasonLength)
+	}
+	if len(j.TicketID) > maxJustificationTicketIDLength {
+		return errors.Errorf("ticket ID must not be longer than %d characters", maxJustificationTicketIDLength)
+	}
+
+	return nil
+}
+
+// parseJustification returns the justification given for the request, or nil
+// if there is none. The headers take precedence over the request body, which is
+// left intact for the handler to read.
+func parseJustification(w http.ResponseWriter, r *http.Request) (*Justification, error) {
+	justification := &Justification{
+		Reason:   strings.TrimSpace(r.Header.Get(HeaderJustification)),
+		TicketID: strings.TrimSpace(r.Header.Get(HeaderTicketID)),
+	}
+
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	}
+
+	if justification.Reason == "" && r.Body != nil && r.Method != http.MethodGet {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read request body")
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		// Malformed bodies are left for the handler to reject.
+		var request struct {
+			Justification *Justification `json:"justification"`
+		}
+		if json.Unmarshal(body, &request) == nil && request.Justification != nil {
+			justification.Reason = strings.TrimSpace(request.Justification.Reason)
+			if justification.TicketID == "" {
+				justification.TicketID = strings.TrimSpace(request.Justification.TicketID)
+			}
+		}
+	}
+
+	if justification.Reason == "" && justification.TicketID == "" {
+		return nil, nil
+	}
+
+	err := justification.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return justification, nil
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+	"github.com/mattermost/pillar/utils"
+)
+
+func TestParseJustification(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		body     string
+		expected *Justification
+		err      bool
+	}{
+		{"none", http.MethodGet, nil, "", nil, false},
+		{"header", http.MethodGet, map[string]string{HeaderJustification: " customer asked "}, "", &Justification{Reason: "customer asked"}, false},
+		{"header with ticket", http.MethodGet, map[string]string{HeaderJustification: "customer asked", HeaderTicketID: "12345"}, "", &Justification{Reason: "customer asked", TicketID: "12345"}, false},
+		{"ticket without reason", http.MethodGet, map[string]string{HeaderTicketID: "12345"}, "", nil, true},
+		{"reason too long", http.MethodGet, map[string]string{HeaderJustification: strings.Repeat("a", 1025)}, "", nil, true},
+		{"body", http.MethodPut, nil, `{"size":"1000users","justification":{"reason":"customer asked","ticket_id":"12345"}}`, &Justification{Reason: "customer asked", TicketID: "12345"}, false},
+		{"header takes precedence over body", http.MethodPut, map[string]string{HeaderJustification: "from header"}, `{"justification":{"reason":"from body"}}`, &Justification{Reason: "from header"}, false},
+		{"body without justification", http.MethodPut, nil, `{"size":"1000users"}`, nil, false},
+		{"malformed body", http.MethodPut, nil, `{"size":`, nil, false},
+		{"body too large", http.MethodPut, nil, strings.Repeat(" ", maxRequestBodySize+1), nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/api/v1/workspaces/workspaceid", strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			justification, err := parseJustification(httptest.NewRecorder(), r)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, justification)
+
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
+
+func TestRequireJustification(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+	auditStore := &memoryAuditStore{}
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+		AuditStore:  auditStore,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("missing justification", func(t *testing.T) {
+		client := NewClient(ts.URL)
+
+		workspace, err := client.GetWorkspace("installationid")
+		assert.EqualError(t, err, "failed with status code 400: a justification must be provided with the X-Pillar-Justification header")
+		assert.Nil(t, workspace)
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.Equal(t, 400, record.StatusCode)
+	})
+
+	t.Run("not required for listing", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallations(gomock.Any(), gomock.Any()).Times(1).Return([]*cloud.InstallationDTO{}, nil)
+
+		workspaces, err := NewClient(ts.URL).ListWorkspaces(&cloud.GetInstallationsRequest{})
+		assert.NoError(t, err)
+		assert.NotNil(t, workspaces)
+	})
+
+	t.Run("header", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+		mockCloudClient.EXPECT().UpdateInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+
+		client := NewClient(ts.URL)
+		client.SetJustification("customer asked for an upgrade", "12345")
+
+		_, err := client.UpdateWorkspace("installationid", &UpdateWorkspaceRequest{Version: utils.NewString("5.31.0")})
+		require.NoError(t, err)
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.Equal(t, "customer asked for an upgrade", record.Justification)
+		assert.Equal(t, "12345", record.TicketID)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+		mockCloudClient.EXPECT().UpdateInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Eq(&cloud.PatchInstallationRequest{Size: utils.NewString("1000users")})).Times(1).Return(&cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}, nil)
+
+		body := []byte(`{"size":"1000users","justification":{"reason":"customer asked to scale up"}}`)
+		req, err := http.NewRequest(http.MethodPut, ts.URL+"/api/v1/workspaces/installationid", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		record := auditStore.last()
+		require.NotNil(t, record)
+		assert.Equal(t, "customer asked to scale up", record.Justification)
+		assert.Empty(t, record.TicketID)
+	})
+
+	t.Run("cleared justification", func(t *testing.T) {
+		client := NewClient(ts.URL)
+		client.SetJustification("customer asked", "12345")
+		client.SetJustification("", "")
+
+		_, err := client.GetWorkspace("installationid")
+		assert.EqualError(t, err, "failed with status code 400: a justification must be provided with the X-Pillar-Justification header")
+	})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// licenseSignatureLength is the length of the signature trailing the
+// license data of a signed Mattermost license.
+const licenseSignatureLength = 256
+
+// workspaceLicense holds the fields of a Mattermost license that matter to support.
+type workspaceLicense struct {
+	ID           string `json:"id"`
+	ExpiresAt    int64  `json:"expires_at"`
+	SkuName      string `json:"sku_name"`
+	SkuShortName string `json:"sku_short_name"`
+	Features     struct {
+		Users *int `json:"users"`
+	} `json:"features"`
+}
+
+// parseLicense decodes a base64 encoded, signed Mattermost license.
+//
+// The signature is not verified: the license comes from the provisioner and
+// is only read to describe the workspace, never to grant anything.
+func parseLicense(encoded string) (*workspaceLicense, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode license")
+	}
+
+	decoded = bytes.TrimRight(decoded, "\x00")
+	if len(decoded) <= licenseSignatureLength {
+		return nil, errors.New("license is too short to be signed")
+	}
+
+	license := &workspaceLicense{}
+	err = json.Unmarshal(decoded[:len(decoded)-licenseSignatureLength], license)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse license")
+	}
+
+	return license, nil
+}
+
+// edition maps the SKU of the license to a workspace edition, returning an
+// empty string for SKUs it does not know.
+func (l *workspaceLicense) edition() string {
+	switch strings.TrimPrefix(strings.ToLower(l.SkuShortName), "cloud-") {
+	case "starter", "free":
+		return WorkspaceEditionFree
+	case "professional", "e10":
+		return WorkspaceEditionProfessional
+	case "enterprise", "e20":
+		return WorkspaceEditionEnterprise
+	}
+
+	return ""
+}
+
+// seats returns the number of users the license allows, or 0 if unlimited.
+func (l *workspaceLicense) seats() int {
+	if l.Features.Users == nil {
+		return 0
+	}
+
+	return *l.Features.Users
+}
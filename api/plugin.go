@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// pluginScanConcurrency limits the number of workspaces whose plugins are
+// listed at once when finding the workspaces running a plugin.
+const pluginScanConcurrency = 10
+
+// MaxPluginScanPerPage is the largest page of cluster installations searched
+// at once when finding the workspaces running a plugin.
+const MaxPluginScanPerPage = 100
+
+// initPlugin registers fleet-wide plugin endpoints on the given router.
+func initPlugin(apiRouter *mux.Router, context *Context) {
+	pluginsRouter := apiRouter.PathPrefix("/plugins").Subrouter()
+	pluginsRouter.Handle("/{plugin}/workspaces", newAPIHandler(context, handleListPluginWorkspaces, PermissionWorkspacePluginRead)).Methods("GET")
+}
+
+// PluginWorkspace is a workspace found while searching for a plugin.
+type PluginWorkspace struct {
+	WorkspaceID           string `json:"workspace_id"`
+	ClusterInstallationID string `json:"cluster_installation_id"`
+	// Plugin is the plugin as installed on the workspace.
+	Plugin *Plugin `json:"plugin,omitempty"`
+	// Error explains why the plugins of the workspace could not be listed.
+	Error string `json:"error,omitempty"`
+}
+
+// PluginWorkspaces is a page of the search for the workspaces running a plugin.
+type PluginWorkspaces struct {
+	// Workspaces are the workspaces of the page running the plugin.
+	Workspaces []*PluginWorkspace `json:"workspaces"`
+	// Failed are the workspaces of the page whose plugins could not be listed.
+	Failed []*PluginWorkspace `json:"failed"`
+	// Scanned is the number of cluster installations searched. Fewer than
+	// per_page are searched on the last page.
+	Scanned int `json:"scanned"`
+}
+
+// handleListPluginWorkspaces responds to GET /api/v1/plugins/{plugin}/workspaces, finding the workspaces that have
+// a plugin installed, optionally at the version given by the version parameter.
+//
+// Every workspace is asked for its plugins with mmctl, so a page covers a page of cluster installations rather than
+// a page of matches. Pages hold at most 100 cluster installations, and the cluster installations searched count
+// against the plugin scan rate limit.
+func handleListPluginWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pluginID := vars["plugin"]
+	version := r.URL.Query().Get("version")
+	c.Logger = c.Logger.WithFields(logrus.Fields{"plugin": pluginID, "version": version})
+
+	if !pluginIDPattern.MatchString(pluginID) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid plugin ID %q", pluginID))
+		return
+	}
+	if version != "" && !versionPattern.MatchString(version) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid version %q", version))
+		return
+	}
+
+	page, perPage, err := parsePaging(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if perPage > MaxPluginScanPerPage {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("per_page must not be more than %d when searching for plugins", MaxPluginScanPerPage))
+		return
+	}
+
+	if c.PluginScanLimiter != nil {
+		allowed, wait := c.PluginScanLimiter.Allow(perPage)
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			c.writeAndLogError(w, errors.Errorf("too many workspaces searched for plugins, retry in %s", wait.Round(time.Second)))
+			return
+		}
+	}
+
+	clusterInstallations, err := c.CloudClient.GetClusterInstallations(r.Context(), &cloud.GetClusterInstallationsRequest{
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	resp := &PluginWorkspaces{
+		Workspaces: []*PluginWorkspace{},
+		Failed:     []*PluginWorkspace{},
+		Scanned:    len(clusterInstallations),
+	}
+	for _, pluginWorkspace := range scanPluginWorkspaces(r.Context(), c.CloudClient, clusterInstallations, pluginID) {
+		switch {
+		case pluginWorkspace.Error != "":
+			resp.Failed = append(resp.Failed, pluginWorkspace)
+		case pluginWorkspace.Plugin == nil:
+		case version != "" && pluginWorkspace.Plugin.Version != version:
+		default:
+			resp.Workspaces = append(resp.Workspaces, pluginWorkspace)
+		}
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// scanPluginWorkspaces lists the plugins of each of the given cluster
+// installations, returning the plugin with the given ID of each, preserving
+// their order.
+func scanPluginWorkspaces(ctx context.Context, client CloudClient, clusterInstallations []*cloud.ClusterInstallation, pluginID string) []*PluginWorkspace {
+	pluginWorkspaces := make([]*PluginWorkspace, len(clusterInstallations))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, pluginScanConcurrency)
+	for i, clusterInstallation := range clusterInstallations {
+		wg.Add(1)
+		go func(i int, clusterInstallation *cloud.ClusterInstallation) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			pluginWorkspaces[i] = &PluginWorkspace{
+				WorkspaceID:           clusterInstallation.InstallationID,
+				ClusterInstallationID: clusterInstallation.ID,
+			}
+
+			plugins, err := getPluginsForClusterInstallation(ctx, client, clusterInstallation.ID)
+			if err != nil {
+				pluginWorkspaces[i].Error = err.Error()
+				return
+			}
+			pluginWorkspaces[i].Plugin = findPlugin(plugins, pluginID)
+		}(i, clusterInstallation)
+	}
+	wg.Wait()
+
+	return pluginWorkspaces
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestPluginWorkspaces(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	mockClusterInstallations := []*cloud.ClusterInstallation{
+		{ID: "clusterinstallation1", InstallationID: "installation1"},
+		{ID: "clusterinstallation2", InstallationID: "installation2"},
+		{ID: "clusterinstallation3", InstallationID: "installation3"},
+		{ID: "clusterinstallation4", InstallationID: "installation4"},
+	}
+	expectScan := func() {
+		mockCloudClient.EXPECT().
+			GetClusterInstallations(gomock.Any(), gomock.Eq(&cloud.GetClusterInstallationsRequest{Page: 1, PerPage: 4})).
+			Times(1).
+			Return(mockClusterInstallations, nil)
+		for clusterInstallationID, output := range map[string]string{
+			"clusterinstallation1": `{"active":[{"id":"jira","version":"3.0.1"}],"inactive":[]}`,
+			"clusterinstallation2": `{"active":[],"inactive":[{"id":"jira","version":"3.0.0"}]}`,
+			"clusterinstallation3": `{"active":[{"id":"github","version":"2.0.0"}],"inactive":[]}`,
+		} {
+			mockCloudClient.EXPECT().
+				ExecClusterInstallationCLI(gomock.Any(), gomock.Eq(clusterInstallationID), gomock.Eq("mmctl"), gomock.Eq(pluginListSubcommand)).
+				Times(1).
+				Return([]byte(output), nil)
+		}
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallation4"), gomock.Eq("mmctl"), gomock.Eq(pluginListSubcommand)).
+			Times(1).
+			Return(nil, errors.New("pod not ready"))
+	}
+
+	t.Run("any version", func(t *testing.T) {
+		expectScan()
+
+		pluginWorkspaces, err := client.ListPluginWorkspaces("jira", "", 1, 4)
+		require.NoError(t, err)
+		assert.Equal(t, 4, pluginWorkspaces.Scanned)
+		require.Len(t, pluginWorkspaces.Workspaces, 2)
+		assert.Equal(t, "installation1", pluginWorkspaces.Workspaces[0].WorkspaceID)
+		assert.True(t, pluginWorkspaces.Workspaces[0].Plugin.Enabled)
+		assert.Equal(t, "installation2", pluginWorkspaces.Workspaces[1].WorkspaceID)
+		assert.False(t, pluginWorkspaces.Workspaces[1].Plugin.Enabled)
+		require.Len(t, pluginWorkspaces.Failed, 1)
+		assert.Equal(t, "installation4", pluginWorkspaces.Failed[0].WorkspaceID)
+		assert.Equal(t, "pod not ready", pluginWorkspaces.Failed[0].Error)
+	})
+
+	t.Run("single version", func(t *testing.T) {
+		expectScan()
+
+		pluginWorkspaces, err := client.ListPluginWorkspaces("jira", "3.0.0", 1, 4)
+		require.NoError(t, err)
+		require.Len(t, pluginWorkspaces.Workspaces, 1)
+		assert.Equal(t, "installation2", pluginWorkspaces.Workspaces[0].WorkspaceID)
+		assert.Equal(t, "3.0.0", pluginWorkspaces.Workspaces[0].Plugin.Version)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		pluginWorkspaces, err := client.ListPluginWorkspaces("jira", "-1", 0, 100)
+		assert.EqualError(t, err, "failed with status code 400: invalid version \"-1\"")
+		assert.Nil(t, pluginWorkspaces)
+	})
+
+	t.Run("page too large", func(t *testing.T) {
+		pluginWorkspaces, err := client.ListPluginWorkspaces("jira", "", 0, 1000)
+		assert.EqualError(t, err, "failed with status code 400: per_page must not be more than 100 when searching for plugins")
+		assert.Nil(t, pluginWorkspaces)
+	})
+}
+
+func TestPluginWorkspacesRateLimit(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:            logger,
+		CloudClient:       mockCloudClient,
+		PluginScanLimiter: NewRateLimiter(0.1, 10),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return([]*cloud.ClusterInstallation{}, nil)
+	pluginWorkspaces, err := client.ListPluginWorkspaces("jira", "", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pluginWorkspaces.Scanned)
+
+	pluginWorkspaces, err = client.ListPluginWorkspaces("jira", "", 1, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed with status code 429: too many workspaces searched for plugins")
+	assert.Nil(t, pluginWorkspaces)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket, allowing a sustained rate of work with
+// bursts of up to its capacity.
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing the given number of units of
+// work per second, in bursts of up to burst units. It starts out full.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:     perSecond,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		now:      time.Now,
+	}
+}
+
+// Allow takes n units of work from the limiter if they are available. If not,
+// nothing is taken and the time until they will be is returned.
+func (l *RateLimiter) Allow(n int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+	}
+	l.last = now
+
+	missing := float64(n) - l.tokens
+	if missing <= 0 {
+		l.tokens -= float64(n)
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+
+	return false, time.Duration(missing / l.rate * float64(time.Second))
+}
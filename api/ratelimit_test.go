@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(10, 100)
+	limiter.now = func() time.Time { return now }
+
+	allowed, _ := limiter.Allow(60)
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow(40)
+	assert.True(t, allowed)
+
+	allowed, wait := limiter.Allow(50)
+	assert.False(t, allowed)
+	assert.Equal(t, 5*time.Second, wait)
+
+	now = now.Add(5 * time.Second)
+	allowed, _ = limiter.Allow(50)
+	assert.True(t, allowed)
+
+	t.Run("refills up to its capacity", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		allowed, _ := limiter.Allow(100)
+		assert.True(t, allowed)
+		allowed, _ = limiter.Allow(1)
+		assert.False(t, allowed)
+	})
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Permission is an action that may be performed through the API.
+type Permission string
+
+const (
+	// PermissionWorkspaceRead allows listing and viewing workspaces.
+	PermissionWorkspaceRead Permission = "workspace:read"
+	// PermissionWorkspaceUpdate allows changing the version, image or size of workspaces.
+	PermissionWorkspaceUpdate Permission = "workspace:update"
+	// PermissionWorkspaceHibernate allows hibernating and waking workspaces.
+	PermissionWorkspaceHibernate Permission = "workspace:hibernate"
+	// PermissionWorkspaceDelete allows deleting Cloud Free and Cloud Professional workspaces.
+	PermissionWorkspaceDelete Permission = "workspace:delete"
+	// PermissionWorkspaceDeleteEnterprise allows deleting Cloud Enterprise workspaces,
+	// which run on isolated infrastructure.
+	PermissionWorkspaceDeleteEnterprise Permission = "workspace:delete:enterprise"
+	// PermissionWorkspaceConfigWrite allows changing workspace config settings.
+	PermissionWorkspaceConfigWrite Permission = "workspace:config:write"
+	// PermissionWorkspaceConfigUnredacted allows viewing the cleartext values of redacted config settings.
+	PermissionWorkspaceConfigUnredacted Permission = "workspace:config:unredacted"
+	// PermissionWorkspaceUserRead allows looking up the users of workspaces.
+	PermissionWorkspaceUserRead Permission = "workspace:user:read"
+	// PermissionWorkspaceUserWrite allows running account recovery actions on
+	// workspace users, such as resetting their password or MFA.
+	PermissionWorkspaceUserWrite Permission = "workspace:user:write"
+	// PermissionWorkspaceUserPromote allows making workspace users system admins.
+	PermissionWorkspaceUserPromote Permission = "workspace:user:promote"
+	// PermissionWorkspaceContentRead allows inspecting the teams and channels of workspaces.
+	PermissionWorkspaceContentRead Permission = "workspace:content:read"
+	// PermissionWorkspaceContentWrite allows restoring, moving and renaming the channels of workspaces.
+	PermissionWorkspaceContentWrite Permission = "workspace:content:write"
+	// PermissionWorkspacePluginRead allows listing the plugins of workspaces,
+	// and finding the workspaces running a plugin.
+	PermissionWorkspacePluginRead Permission = "workspace:plugin:read"
+	// PermissionWorkspacePluginWrite allows enabling, disabling and installing the plugins of workspaces.
+	PermissionWorkspacePluginWrite Permission = "workspace:plugin:write"
+	// PermissionWorkspaceSupportPacket allows downloading support packets of workspaces.
+	PermissionWorkspaceSupportPacket Permission = "workspace:support-packet"
+	// PermissionWorkspaceLogRead allows reading the recent server logs of
+	// workspaces, which are included in support packets. Logs are not redacted
+	// and may hold user data.
+	PermissionWorkspaceLogRead Permission = "workspace:log:read"
+	// PermissionGroupRead allows listing and viewing groups.
+	PermissionGroupRead Permission = "group:read"
+	// PermissionClusterRead allows listing clusters and the workspaces they host.
+	PermissionClusterRead Permission = "cluster:read"
+	// PermissionAuditRead allows viewing the audit log.
+	PermissionAuditRead Permission = "audit:read"
+)
+
+const (
+	// RoleViewer may view workspaces.
+	RoleViewer = "viewer"
+	// RoleSupportEditor may view and make routine changes to workspaces.
+	RoleSupportEditor = "support-editor"
+	// RoleAdmin may perform every action.
+	RoleAdmin = "admin"
+)
+
+// DefaultRoles are the built-in roles and their permissions. They can be
+// extended or overridden through the RBAC config.
+var DefaultRoles = map[string][]Permission{
+	RoleViewer: {
+		PermissionWorkspaceRead,
+		PermissionWorkspaceUserRead,
+		PermissionWorkspaceContentRead,
+		PermissionWorkspacePluginRead,
+		PermissionGroupRead,
+		PermissionClusterRead,
+	},
+	RoleSupportEditor: {
+		PermissionWorkspaceRead,
+		PermissionWorkspaceUserRead,
+		PermissionWorkspaceContentRead,
+		PermissionWorkspacePluginRead,
+		PermissionGroupRead,
+		PermissionClusterRead,
+		PermissionWorkspaceUpdate,
+		PermissionWorkspaceHibernate,
+		PermissionWorkspaceDelete,
+		PermissionWorkspaceUserWrite,
+		PermissionWorkspaceContentWrite,
+		PermissionWorkspacePluginWrite,
+		PermissionWorkspaceSupportPacket,
+		PermissionWorkspaceConfigWrite,
+	},
+	RoleAdmin: {
+		PermissionWorkspaceRead,
+		PermissionWorkspaceUserRead,
+		PermissionWorkspaceContentRead,
+		PermissionWorkspacePluginRead,
+		PermissionGroupRead,
+		PermissionClusterRead,
+		PermissionWorkspaceUpdate,
+		PermissionWorkspaceHibernate,
+		PermissionWorkspaceDelete,
+		PermissionWorkspaceDeleteEnterprise,
+		PermissionWorkspaceUserWrite,
+		PermissionWorkspaceContentWrite,
+		PermissionWorkspacePluginWrite,
+		PermissionWorkspaceSupportPacket,
+		PermissionWorkspaceLogRead,
+		PermissionWorkspaceUserPromote,
+		PermissionWorkspaceConfigWrite,
+		PermissionWorkspaceConfigUnredacted,
+		PermissionAuditRead,
+	},
+}
+
+// RoleBinding grants a role to the identities matching any of its subjects or groups.
+type RoleBinding struct {
+	Role string `json:"role"`
+	// Subjects match either the subject or the email address of an identity.
+	Subjects []string `json:"subjects"`
+	Groups   []string `json:"groups"`
+}
+
+// RBACConfig holds the role definitions and role bindings of the server.
+type RBACConfig struct {
+	// Roles adds to or overrides the DefaultRoles.
+	Roles        map[string][]Permission `json:"roles"`
+	Bindings     []RoleBinding           `json:"bindings"`
+	DefaultRoles []string                `json:"default_roles"`
+}
+
+// LoadRBACConfig reads an RBAC config from the given JSON file.
+func LoadRBACConfig(path string) (*RBACConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open rbac config")
+	}
+	defer file.Close()
+
+	config := &RBACConfig{}
+	err = json.NewDecoder(file).Decode(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode rbac config")
+	}
+
+	return config, nil
+}
+
+// Authorizer maps authenticated identities to roles and checks their permissions.
+type Authorizer struct {
+	roles        map[string]map[Permission]bool
+	bindings     []RoleBinding
+	defaultRoles []string
+}
+
+// NewAuthorizer creates an authorizer from the given config, validating that
+// every referenced role is defined.
+func NewAuthorizer(config *RBACConfig) (*Authorizer, error) {
+	if config == nil {
+		config = &RBACConfig{}
+	}
+
+	roles := make(map[string]map[Permission]bool)
+	addRoles := func(definitions map[string][]Permission) {
+		for role, permissions := range definitions {
+			roles[role] = make(map[Permission]bool, len(permissions))
+			for _, permission := range permissions {
+				roles[role][permission] = true
+			}
+		}
+	}
+	addRoles(DefaultRoles)
+	addRoles(config.Roles)
+
+	for _, binding := range config.Bindings {
+		if _, ok := roles[binding.Role]; !ok {
+			return nil, errors.Errorf("role binding references unknown role %s", binding.Role)
+		}
+	}
+	for _, role := range config.DefaultRoles {
+		if _, ok := roles[role]; !ok {
+			return nil, errors.Errorf("default roles reference unknown role %s", role)
+		}
+	}
+
+	return &Authorizer{
+		roles:        roles,
+		bindings:     config.Bindings,
+		defaultRoles: config.DefaultRoles,
+	}, nil
+}
+
+// RolesFor returns the roles granted to the given identity.
+func (a *Authorizer) RolesFor(identity *Identity) []string {
+	if identity == nil {
+		return nil
+	}
+
+	granted := make(map[string]bool)
+	roles := []string{}
+	grant := func(role string) {
+		if !granted[role] {
+			granted[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	for _, role := range a.defaultRoles {
+		grant(role)
+	}
+	for _, binding := range a.bindings {
+		if bindingMatches(binding, identity) {
+			grant(binding.Role)
+		}
+	}
+
+	return roles
+}
+
+func bindingMatches(binding RoleBinding, identity *Identity) bool {
+	for _, subject := range binding.Subjects {
+		if subject == identity.Subject || (identity.Email != "" && subject == identity.Email) {
+			return true
+		}
+	}
+	for _, bindingGroup := range binding.Groups {
+		for _, group := range identity.Groups {
+			if bindingGroup == group {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasPermission returns true if any of the given roles grants the permission.
+func (a *Authorizer) HasPermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		if a.roles[role][permission] {
+			return true
+		}
+	}
+
+	return false
+}
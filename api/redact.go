@@ -0,0 +1,248 @@
+package api
+
+import (
+	"path"
+	"strings"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// RedactedValue replaces sensitive values that have been redacted. It matches
+// the placeholder used by the Mattermost server when sanitizing its config.
+const RedactedValue = "********************************"
+
+// DefaultConfigRedactionDenylist contains the paths of workspace config
+// settings that are known to carry credentials or other customer secrets.
+//
+// Each path is a dot separated list of config keys. Every segment is matched
+// against a single key using path.Match, so segments may contain wildcards such
+// as "*" or "*Password". A path that matches an object redacts every value
+// nested within it.
+var DefaultConfigRedactionDenylist = []string{
+	"SqlSettings.DataSource",
+	"SqlSettings.DataSourceReplicas",
+	"SqlSettings.DataSourceSearchReplicas",
+	"SqlSettings.AtRestEncryptKey",
+	"FileSettings.PublicLinkSalt",
+	"FileSettings.AmazonS3SecretAccessKey",
+	"EmailSettings.SMTPPassword",
+	"GitLabSettings.Secret",
+	"GoogleSettings.Secret",
+	"Office365Settings.Secret",
+	"OpenIdSettings.Secret",
+	"LdapSettings.BindPassword",
+	"ElasticsearchSettings.Password",
+	"MessageExportSettings.GlobalRelaySettings.SmtpPassword",
+	"ServiceSettings.GfycatApiSecret",
+	"ServiceSettings.GoogleDeveloperKey",
+	"ServiceSettings.SplitKey",
+	"ImageProxySettings.RemoteImageProxyOptions",
+	"PluginSettings.Plugins",
+	"*.*Password",
+	"*.*Secret",
+}
+
+// sensitiveEnvVarPatterns match the upper cased names of environment variables
+// that likely carry credentials, regardless of the config setting they map to.
+var sensitiveEnvVarPatterns = []string{
+	"*PASSWORD*",
+	"*SECRET*",
+	"*TOKEN*",
+	"*KEY*",
+	"*DATASOURCE*",
+	"*CREDENTIAL*",
+}
+
+// Redactor masks sensitive values in workspace configuration.
+type Redactor struct {
+	denylist  [][]string
+	allowlist [][]string
+	// envDenylist and envAllowlist are the upper cased lists, matched against
+	// the names of environment variables overriding config settings.
+	envDenylist  [][]string
+	envAllowlist [][]string
+}
+
+// NewRedactor creates a redactor that masks every config path matching the
+// denylist, except for those matching the allowlist. Allowing a path also
+// allows everything nested within it.
+func NewRedactor(denylist, allowlist []string) *Redactor {
+	return &Redactor{
+		denylist:     splitConfigPaths(denylist),
+		allowlist:    splitConfigPaths(allowlist),
+		envDenylist:  splitConfigPaths(upperAll(denylist)),
+		envAllowlist: splitConfigPaths(upperAll(allowlist)),
+	}
+}
+
+func upperAll(values []string) []string {
+	upper := make([]string, len(values))
+	for i, value := range values {
+		upper[i] = strings.ToUpper(value)
+	}
+
+	return upper
+}
+
+var defaultRedactor = NewRedactor(DefaultConfigRedactionDenylist, nil)
+
+func splitConfigPaths(paths []string) [][]string {
+	var split [][]string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		split = append(split, strings.Split(p, "."))
+	}
+
+	return split
+}
+
+// IsRedacted returns true if the value at the given dot separated config path
+// would be redacted.
+func (r *Redactor) IsRedacted(configPath string) bool {
+	keys := strings.Split(configPath, ".")
+	for i := range keys {
+		if r.isDenied(keys[:i+1]) {
+			return !r.isAllowed(keys)
+		}
+	}
+
+	return false
+}
+
+// RedactConfig masks all sensitive values of the given config in place and
+// returns it.
+func (r *Redactor) RedactConfig(config map[string]interface{}) map[string]interface{} {
+	r.redactObject(config, nil, false)
+	return config
+}
+
+func (r *Redactor) redactObject(object map[string]interface{}, keys []string, denied bool) {
+	for key, value := range object {
+		valueKeys := append(keys[:len(keys):len(keys)], key)
+		valueDenied := denied || r.isDenied(valueKeys)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			r.redactObject(nested, valueKeys, valueDenied)
+			continue
+		}
+
+		if valueDenied && !r.isAllowed(valueKeys) {
+			object[key] = redactValue(value)
+		}
+	}
+}
+
+// redactValue masks a single value. Empty values are preserved so that it
+// remains visible whether a setting has been configured at all.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return v
+		}
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i := range v {
+			redacted[i] = redactValue(v[i])
+		}
+		return redacted
+	}
+
+	return RedactedValue
+}
+
+// IsEnvVarRedacted returns true if the value of the named environment variable
+// would be redacted. Variables overriding config settings, such as
+// MM_SQLSETTINGS_DATASOURCE, follow the config denylist and allowlist. Any
+// other variable whose name suggests a credential is redacted as well.
+func (r *Redactor) IsEnvVarRedacted(name string) bool {
+	name = strings.ToUpper(name)
+
+	keys := []string{name}
+	if strings.HasPrefix(name, "MM_") {
+		keys = strings.Split(strings.TrimPrefix(name, "MM_"), "_")
+	}
+
+	for i := range keys {
+		if matchesAnyConfigPath(r.envAllowlist, keys[:i+1]) {
+			return false
+		}
+	}
+	for i := range keys {
+		if matchesAnyConfigPath(r.envDenylist, keys[:i+1]) {
+			return true
+		}
+	}
+	for _, pattern := range sensitiveEnvVarPatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactEnv returns a copy of the given environment variables with the values
+// of sensitive variables masked. References to values held elsewhere, such as
+// in Kubernetes secrets, are kept as they do not reveal the values themselves.
+func (r *Redactor) RedactEnv(env cloud.EnvVarMap) cloud.EnvVarMap {
+	if env == nil {
+		return nil
+	}
+
+	redacted := make(cloud.EnvVarMap, len(env))
+	for name, envVar := range env {
+		if envVar.Value != "" && r.IsEnvVarRedacted(name) {
+			envVar.Value = RedactedValue
+		}
+		redacted[name] = envVar
+	}
+
+	return redacted
+}
+
+func (r *Redactor) isDenied(keys []string) bool {
+	return matchesAnyConfigPath(r.denylist, keys)
+}
+
+// isAllowed returns true if the allowlist matches the given path or any of
+// its parents.
+func (r *Redactor) isAllowed(keys []string) bool {
+	for i := range keys {
+		if matchesAnyConfigPath(r.allowlist, keys[:i+1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAnyConfigPath(patterns [][]string, keys []string) bool {
+	for _, pattern := range patterns {
+		if matchesConfigPath(pattern, keys) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesConfigPath(pattern []string, keys []string) bool {
+	if len(pattern) != len(keys) {
+		return false
+	}
+
+	for i := range pattern {
+		matched, err := path.Match(pattern[i], keys[i])
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// dockerHubRegistry is the registry of images that do not name one.
+const dockerHubRegistry = "registry-1.docker.io"
+
+// registryTimeout limits how long checking a single tag may take.
+const registryTimeout = 15 * time.Second
+
+// manifestMediaTypes are the manifest formats accepted when checking that a tag exists.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// challengeParamPattern matches the parameters of a WWW-Authenticate challenge, e.g. realm="https://auth.docker.io/token".
+var challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ImageRegistry checks image tags before workspaces are changed to use them.
+type ImageRegistry interface {
+	// TagExists returns true if the image has the given tag.
+	TagExists(ctx context.Context, image, tag string) (bool, error)
+}
+
+// DockerRegistry is an ImageRegistry speaking the Docker Registry HTTP API V2,
+// authenticating anonymously where the registry requires a token.
+type DockerRegistry struct {
+	client *http.Client
+}
+
+// NewDockerRegistry creates an ImageRegistry checking tags with the given client, or a default client if nil.
+func NewDockerRegistry(client *http.Client) *DockerRegistry {
+	if client == nil {
+		client = &http.Client{Timeout: registryTimeout}
+	}
+
+	return &DockerRegistry{client: client}
+}
+
+// parseImage splits an image into the host of its registry and its repository
+// within the registry, e.g. mattermost/mattermost-enterprise-edition is the
+// repository of the same name on Docker Hub.
+func parseImage(image string) (string, string) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 1 {
+		return dockerHubRegistry, "library/" + image
+	}
+	if strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost" {
+		return parts[0], parts[1]
+	}
+
+	return dockerHubRegistry, image
+}
+
+// TagExists returns true if the manifest of the image tag can be found in its registry.
+func (r *DockerRegistry) TagExists(ctx context.Context, image, tag string) (bool, error) {
+	host, repository := parseImage(image)
+	u := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, repository, url.PathEscape(tag))
+
+	resp, err := r.headManifest(ctx, u, "")
+	if err != nil {
+		return false, err
+	}
+	closeBody(resp)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		var token string
+		token, err = r.fetchToken(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return false, err
+		}
+
+		resp, err = r.headManifest(ctx, u, token)
+		if err != nil {
+			return false, err
+		}
+		closeBody(resp)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+
+	return false, errors.Errorf("registry %s responded with status code %d", host, resp.StatusCode)
+}
+
+func (r *DockerRegistry) headManifest(ctx context.Context, u, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reach image registry")
+	}
+
+	return resp, nil
+}
+
+// registryToken is the response of a registry token server.
+type registryToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// fetchToken requests an anonymous pull token as described by the Bearer
+// challenge of the registry.
+func (r *DockerRegistry) fetchToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errors.Errorf("unsupported registry authentication %q", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.New("registry authentication challenge has no realm")
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse registry authentication realm")
+	}
+	query := u.Query()
+	for _, name := range []string{"service", "scope"} {
+		if params[name] != "" {
+			query.Set(name, params[name])
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to reach registry token server")
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("registry token server responded with status code %d", resp.StatusCode)
+	}
+
+	token := &registryToken{}
+	err = decodeJSON(token, resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode registry token")
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+
+	return token.AccessToken, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImage(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"mattermost/mattermost-enterprise-edition": {dockerHubRegistry, "mattermost/mattermost-enterprise-edition"},
+		"ubuntu":                          {dockerHubRegistry, "library/ubuntu"},
+		"registry.example.com/mattermost": {"registry.example.com", "mattermost"},
+		"localhost:5000/team/mattermost":  {"localhost:5000", "team/mattermost"},
+	} {
+		host, repository := parseImage(image)
+		assert.Equal(t, expected, [2]string{host, repository}, image)
+	}
+}
+
+func TestDockerRegistry(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "registry", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:mattermost/server:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token":"secret"}`))
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:mattermost/server:pull"`, ts.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/mattermost/server/manifests/5.30.0":
+			assert.Equal(t, http.MethodHead, r.Method)
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/mattermost/server/manifests/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	registry := NewDockerRegistry(ts.Client())
+	image := strings.TrimPrefix(ts.URL, "https://") + "/mattermost/server"
+
+	exists, err := registry.TagExists(context.Background(), image, "5.30.0")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = registry.TagExists(context.Background(), image, "5.300.0")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = registry.TagExists(context.Background(), image, "broken")
+	assert.Error(t, err)
+}
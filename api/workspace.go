@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	cloud "github.com/mattermost/mattermost-cloud/model"
 )
@@ -13,8 +17,27 @@ import (
 // initWorkspace registers workspace endpoints on the given router.
 func initWorkspace(apiRouter *mux.Router, context *Context) {
 	workspacesRouter := apiRouter.PathPrefix("/workspaces").Subrouter()
-	workspacesRouter.Handle("/list", newAPIHandler(context, handleListWorkspaces)).Methods("POST")
-	workspacesRouter.Handle("/{workspace}", newAPIHandler(context, handleGetWorkspace)).Methods("GET")
+	workspacesRouter.Handle("/list", newAPIHandler(context, handleListWorkspaces, PermissionWorkspaceRead)).Methods("POST")
+	workspacesRouter.Handle("/search", newAPIHandler(context, handleSearchWorkspaces, PermissionWorkspaceRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}", newJustifiedAPIHandler(context, handleGetWorkspace, PermissionWorkspaceRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}", newJustifiedAPIHandler(context, handleUpdateWorkspace, PermissionWorkspaceUpdate)).Methods("PUT")
+	workspacesRouter.Handle("/{workspace}", newJustifiedAPIHandler(context, handleDeleteWorkspace, PermissionWorkspaceDelete)).Methods("DELETE")
+	workspacesRouter.Handle("/{workspace}/hibernate", newJustifiedAPIHandler(context, handleHibernateWorkspace, PermissionWorkspaceHibernate)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/wakeup", newJustifiedAPIHandler(context, handleWakeupWorkspace, PermissionWorkspaceHibernate)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/health", newJustifiedAPIHandler(context, handleGetWorkspaceHealth, PermissionWorkspaceRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/support-packet", newJustifiedAPIHandler(context, handleGetWorkspaceSupportPacket, PermissionWorkspaceSupportPacket)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/users", newJustifiedAPIHandler(context, handleListWorkspaceUsers, PermissionWorkspaceUserRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/users/{user}", newJustifiedAPIHandler(context, handleGetWorkspaceUser, PermissionWorkspaceUserRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/users/{user}/actions", newJustifiedAPIHandler(context, handleWorkspaceUserAction, PermissionWorkspaceUserWrite)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/teams", newJustifiedAPIHandler(context, handleListWorkspaceTeams, PermissionWorkspaceContentRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/teams/{team}/channels", newJustifiedAPIHandler(context, handleListWorkspaceTeamChannels, PermissionWorkspaceContentRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/channels/{channel}", newJustifiedAPIHandler(context, handleGetWorkspaceChannel, PermissionWorkspaceContentRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/channels/{channel}/unarchive", newJustifiedAPIHandler(context, handleUnarchiveWorkspaceChannel, PermissionWorkspaceContentWrite)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/channels/{channel}/move", newJustifiedAPIHandler(context, handleMoveWorkspaceChannel, PermissionWorkspaceContentWrite)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/channels/{channel}/rename", newJustifiedAPIHandler(context, handleRenameWorkspaceChannel, PermissionWorkspaceContentWrite)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/plugins", newJustifiedAPIHandler(context, handleListWorkspacePlugins, PermissionWorkspacePluginRead)).Methods("GET")
+	workspacesRouter.Handle("/{workspace}/plugins/{plugin}/actions", newJustifiedAPIHandler(context, handleWorkspacePluginAction, PermissionWorkspacePluginWrite)).Methods("POST")
+	workspacesRouter.Handle("/{workspace}/config", newJustifiedAPIHandler(context, handlePatchWorkspaceConfig, PermissionWorkspaceConfigWrite)).Methods("PATCH")
 }
 
 const (
@@ -29,38 +52,73 @@ const (
 // Workspace is a respresentation of an installation without certain sensitive fields
 // and data catered to be useful to the support team.
 type Workspace struct {
-	ID        string `json:"id"`
-	GroupID   string `json:"group_id"`
+	ID      string `json:"id"`
+	GroupID string `json:"group_id"`
+	// State is the provisioning state of the workspace, such as stable,
+	// update-in-progress, hibernating or creation-failed.
+	State     string `json:"state"`
 	Version   string `json:"version"`
+	Image     string `json:"image"`
 	DNS       string `json:"dns"`
 	Size      string `json:"size"`
+	Affinity  string `json:"affinity"`
 	Database  string `json:"database"`
 	Filestore string `json:"filestore"`
-	CreateAt  int64  `json:"create_at"`
-	DeleteAt  int64  `json:"delete_at"`
-	Edition   string `json:"edition"`
+	// HasLicense is whether a license is installed. The license itself is
+	// never returned, only the details below.
+	HasLicense bool   `json:"has_license"`
+	LicenseID  string `json:"license_id,omitempty"`
+	LicenseSKU string `json:"license_sku,omitempty"`
+	// LicenseSeats is the number of users the license allows, or 0 if unlimited.
+	LicenseSeats     int   `json:"license_seats,omitempty"`
+	LicenseExpiresAt int64 `json:"license_expires_at,omitempty"`
+	// Edition is read from the SKU of the license, falling back to the
+	// affinity should the license be unreadable.
+	Edition       string          `json:"edition"`
+	MattermostEnv cloud.EnvVarMap `json:"mattermost_env,omitempty"`
+	EnvRedacted   bool            `json:"env_redacted"`
+	// LockAcquiredBy is the provisioner instance currently working on the workspace, if any.
+	LockAcquiredBy string `json:"lock_acquired_by,omitempty"`
+	LockAcquiredAt int64  `json:"lock_acquired_at,omitempty"`
+	CreateAt       int64  `json:"create_at"`
+	DeleteAt       int64  `json:"delete_at"`
 }
 
 // handleListWorkspaces responds to POST /api/v1/workspaces/list, listing workspaces that match the filters.
 func handleListWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
-	gi := &cloud.GetInstallationsRequest{}
-	err := decodeJSON(gi, r.Body)
+	listRequest := &ListWorkspacesRequest{}
+	err := decodeJSON(listRequest, r.Body)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		c.writeAndLogError(w, err)
 		return
 	}
 
-	installations, err := c.CloudClient.GetInstallations(gi)
+	err = listRequest.Validate()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
 		c.writeAndLogError(w, err)
 		return
 	}
 
-	resp := convertInstallationsToWorkspaces(installations)
+	var installations []*cloud.InstallationDTO
+	if listRequest.State == "" {
+		installations, err = c.CloudClient.GetInstallations(r.Context(), &listRequest.GetInstallationsRequest)
+	} else {
+		installations, err = getInstallationsByState(r.Context(), c.CloudClient, listRequest)
+	}
+	if err == errStateScanLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
 
-	b, err := json.Marshal(resp)
+	b, err := json.Marshal(convertInstallationsToWorkspaces(installations, c.redactor()))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		c.writeAndLogError(w, err)
@@ -71,28 +129,179 @@ func handleListWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
-// Group is a respresentation of an installation group without certain sensitive fields
-// and data catered to be useful to the support team.
-type Group struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+// stateScanPerPage is the minimum number of installations fetched at once
+// while scanning the provisioner for installations in a given state.
+const stateScanPerPage = 100
+
+// maxStateScanInstallations bounds how many installations are scanned to list
+// the installations in a given state.
+const maxStateScanInstallations = 5000
+
+// errStateScanLimit is returned when listing installations in a given state
+// would scan more than maxStateScanInstallations.
+var errStateScanLimit = errors.Errorf("filtering by state scans at most %d workspaces, filter by owner or group or search workspaces by state instead", maxStateScanInstallations)
+
+// getInstallationsByState returns the requested page of the installations in
+// the state of the request. The provisioner cannot filter by state, so its
+// installations are paged through from the start until the page is filled
+// or every installation has been scanned, failing with errStateScanLimit once
+// maxStateScanInstallations have been scanned.
+func getInstallationsByState(ctx context.Context, client CloudClient, listRequest *ListWorkspacesRequest) ([]*cloud.InstallationDTO, error) {
+	matches := []*cloud.InstallationDTO{}
+
+	// Without a page size, every installation in the state is listed.
+	start, end := 0, -1
+	if listRequest.PerPage > 0 {
+		start = listRequest.Page * listRequest.PerPage
+		end = start + listRequest.PerPage
+	}
+
+	scanRequest := listRequest.GetInstallationsRequest
+	scanRequest.Page = 0
+	if scanRequest.PerPage < stateScanPerPage {
+		scanRequest.PerPage = stateScanPerPage
+	}
+	for scanned := 0; end < 0 || len(matches) < end; scanRequest.Page++ {
+		if scanned >= maxStateScanInstallations {
+			return nil, errStateScanLimit
+		}
+
+		request := scanRequest
+		installations, err := client.GetInstallations(ctx, &request)
+		if err != nil {
+			return nil, err
+		}
+		scanned += len(installations)
+
+		for _, installation := range installations {
+			if installation.State == listRequest.State {
+				matches = append(matches, installation)
+			}
+		}
+
+		if len(installations) < scanRequest.PerPage {
+			break
+		}
+	}
+
+	if start >= len(matches) {
+		return []*cloud.InstallationDTO{}, nil
+	}
+	if end < 0 || end > len(matches) {
+		end = len(matches)
+	}
+
+	return matches[start:end], nil
 }
 
+const (
+	// WorkspaceSectionGroup is the section of a detailed workspace holding its group.
+	WorkspaceSectionGroup = "group"
+	// WorkspaceSectionClusters is the section of a detailed workspace holding its cluster installations.
+	WorkspaceSectionClusters = "clusters"
+	// WorkspaceSectionConfig is the section of a detailed workspace holding its config.
+	WorkspaceSectionConfig = "config"
+)
+
 // WorkspaceDetailed contains a workspace and extra detailed and related data for it.
+//
+// Each section is fetched independently, so a section that could not be
+// fetched carries an error rather than failing the whole workspace.
 type WorkspaceDetailed struct {
 	*Workspace
-	Group  *Group                 `json:"group"`
-	Config map[string]interface{} `json:"config"`
+	Group    *WorkspaceGroup    `json:"group"`
+	Clusters *WorkspaceClusters `json:"clusters"`
+	Config   *WorkspaceConfig   `json:"config"`
+	// CachedAt is the time in milliseconds at which the oldest cached data in
+	// the workspace was fetched from the provisioner, or 0 if none was cached.
+	CachedAt int64 `json:"cached_at,omitempty"`
+}
+
+// WorkspaceGroup is the group section of a detailed workspace. It is nil if
+// the workspace does not belong to a group.
+type WorkspaceGroup struct {
+	*Group
+	Error string `json:"error,omitempty"`
+}
+
+// WorkspaceClusters is the cluster installations section of a detailed workspace.
+type WorkspaceClusters struct {
+	ClusterInstallations []*ClusterInstallation `json:"cluster_installations"`
+	Error                string                 `json:"error,omitempty"`
+}
+
+// WorkspaceConfig is the config section of a detailed workspace.
+type WorkspaceConfig struct {
+	Settings map[string]interface{} `json:"settings"`
+	Redacted bool                   `json:"redacted"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// WorkspaceSectionError is the failure to fetch a single section of a detailed workspace.
+type WorkspaceSectionError struct {
+	Section string
+	Message string
+}
+
+// Error implements error.
+func (e *WorkspaceSectionError) Error() string {
+	return fmt.Sprintf("failed to get workspace %s: %s", e.Section, e.Message)
+}
+
+// Errors returns the sections of the workspace that could not be fetched.
+func (w *WorkspaceDetailed) Errors() []*WorkspaceSectionError {
+	var errs []*WorkspaceSectionError
+	addError := func(section, message string) {
+		if message != "" {
+			errs = append(errs, &WorkspaceSectionError{Section: section, Message: message})
+		}
+	}
+
+	if w.Group != nil {
+		addError(WorkspaceSectionGroup, w.Group.Error)
+	}
+	if w.Clusters != nil {
+		addError(WorkspaceSectionClusters, w.Clusters.Error)
+	}
+	if w.Config != nil {
+		addError(WorkspaceSectionConfig, w.Config.Error)
+	}
+
+	return errs
 }
 
 // handleGetWorkspace responds to GET /api/v1/workspaces/{id}, getting a workspace and a bunch of contextual data for it.
+//
+// Sensitive config values are redacted unless ?unredacted=true is passed, the server allows it and
+// the caller has permission to view them. Sections of contextual data that fail to be fetched are
+// returned with an error instead of failing the request. Data may be served from the cache unless
+// ?refresh=true is passed.
 func handleGetWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	workspaceID := vars["workspace"]
 	c.Logger = c.Logger.WithField("workspace", workspaceID)
 
-	installation, err := c.CloudClient.GetInstallation(workspaceID, &cloud.GetInstallationRequest{IncludeGroupConfig: true})
+	unredacted, err := parseBool(r.URL.Query(), "unredacted", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if unredacted && !c.AllowUnredactedConfig {
+		w.WriteHeader(http.StatusForbidden)
+		c.writeAndLogError(w, errors.New("viewing unredacted workspace config is not allowed"))
+		return
+	}
+	if unredacted && !c.can(PermissionWorkspaceConfigUnredacted) {
+		w.WriteHeader(http.StatusForbidden)
+		c.writeAndLogError(w, errors.Errorf("missing permission %s", PermissionWorkspaceConfigUnredacted))
+		return
+	}
+	if unredacted {
+		c.Logger.Warn("Returning unredacted workspace config")
+	}
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{IncludeGroupConfig: true})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		c.writeAndLogError(w, err)
@@ -103,73 +312,164 @@ func handleGetWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workspace := convertInstallationToWorkspace(installation)
+	workspaceDetailed := &WorkspaceDetailed{
+		Workspace: convertInstallationToWorkspace(installation, c.redactor()),
+		Clusters:  &WorkspaceClusters{},
+		Config:    &WorkspaceConfig{Redacted: !unredacted},
+	}
+	if unredacted {
+		workspaceDetailed.MattermostEnv = installation.MattermostEnv
+		workspaceDetailed.EnvRedacted = false
+	}
 
-	configChan := make(chan error, 1)
-	groupChan := make(chan error, 1)
+	var wg sync.WaitGroup
+	wg.Add(2)
 
-	var config map[string]interface{}
 	go func() {
-		clusterInstallations, err := c.CloudClient.GetClusterInstallations(&cloud.GetClusterInstallationsRequest{InstallationID: workspace.ID, PerPage: 1000})
+		defer wg.Done()
+
+		cloudClusterInstallations, err := getClusterInstallationsForWorkspace(r.Context(), c.CloudClient, workspaceID)
 		if err != nil {
-			configChan <- err
+			c.Logger.WithError(err).Warn("Failed to get workspace cluster installations")
+			workspaceDetailed.Clusters.Error = err.Error()
+			workspaceDetailed.Config.Error = "cluster installations are unavailable"
 			return
 		}
+		workspaceDetailed.Clusters.ClusterInstallations = convertCloudClusterInstallationsToClusterInstallations(cloudClusterInstallations)
 
-		if len(clusterInstallations) == 0 {
-			configChan <- errors.New("workspace does not have a cluster installation")
+		clusterInstallation, err := selectClusterInstallation(cloudClusterInstallations)
+		if err != nil {
+			workspaceDetailed.Config.Error = err.Error()
 			return
 		}
 
-		clusterInstallation := clusterInstallations[0]
-		config, err = getConfigForClusterInstallation(c.CloudClient, clusterInstallation.ID)
+		config, err := getConfigForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
 		if err != nil {
-			configChan <- err
+			c.Logger.WithError(err).Warn("Failed to get workspace config")
+			workspaceDetailed.Config.Error = err.Error()
 			return
 		}
-
-		configChan <- nil
+		if !unredacted {
+			config = c.redactor().RedactConfig(config)
+		}
+		workspaceDetailed.Config.Settings = config
 	}()
 
-	var group *Group
 	go func() {
-		cloudGroup, err := c.CloudClient.GetGroup(workspace.GroupID)
+		defer wg.Done()
+
+		if workspaceDetailed.GroupID == "" {
+			return
+		}
+
+		cloudGroup, err := c.CloudClient.GetGroup(r.Context(), workspaceDetailed.GroupID)
 		if err != nil {
-			groupChan <- err
+			c.Logger.WithError(err).Warn("Failed to get workspace group")
+			workspaceDetailed.Group = &WorkspaceGroup{Group: &Group{ID: workspaceDetailed.GroupID}, Error: err.Error()}
+			return
+		}
+		if cloudGroup == nil {
 			return
 		}
 
-		group = convertCloudGroupToGroup(cloudGroup)
-		groupChan <- nil
+		workspaceDetailed.Group = &WorkspaceGroup{Group: convertCloudGroupToGroup(cloudGroup)}
 	}()
 
-	configErr := <-configChan
-	if configErr != nil {
+	wg.Wait()
+
+	if usage := cacheUsageFromContext(r.Context()); usage != nil {
+		workspaceDetailed.CachedAt = usage.CachedAt()
+	}
+
+	b, err := json.Marshal(workspaceDetailed)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		c.writeAndLogError(w, configErr)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleUpdateWorkspace responds to PUT /api/v1/workspaces/{id}, changing the version, image or size of a workspace.
+//
+// When an image registry is configured, the resulting image tag must exist in it, so that a mistyped version is
+// rejected rather than failing the rollout. Otherwise only the syntax of the version is checked.
+func handleUpdateWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	updateRequest := &UpdateWorkspaceRequest{}
+	err := decodeJSON(updateRequest, r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	err = updateRequest.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
 		return
 	}
 
-	groupErr := <-groupChan
-	if groupErr != nil {
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		c.writeAndLogError(w, groupErr)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
-	workspaceDetailed := &WorkspaceDetailed{
-		Workspace: workspace,
-		Group:     group,
-		Config:    config,
+	if c.ImageRegistry != nil && (updateRequest.Version != nil || updateRequest.Image != nil) {
+		image, version := installation.Image, installation.Version
+		if updateRequest.Image != nil {
+			image = *updateRequest.Image
+		}
+		if updateRequest.Version != nil {
+			version = *updateRequest.Version
+		}
+
+		var exists bool
+		exists, err = c.ImageRegistry.TagExists(r.Context(), image, version)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			c.writeAndLogError(w, errors.Wrapf(err, "failed to check that %s:%s exists", image, version))
+			return
+		}
+		if !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			c.writeAndLogError(w, errors.Errorf("version %q of image %s does not exist", version, image))
+			return
+		}
 	}
 
-	b, err := json.Marshal(workspaceDetailed)
+	c.Logger.WithFields(logrus.Fields{
+		"version": stringValue(updateRequest.Version),
+		"image":   stringValue(updateRequest.Image),
+		"size":    stringValue(updateRequest.Size),
+	}).Info("Updating workspace")
+
+	installation, err = c.CloudClient.UpdateInstallation(r.Context(), workspaceID, updateRequest.toPatchInstallationRequest())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		c.writeAndLogError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	b, err := json.Marshal(convertInstallationToWorkspace(installation, c.redactor()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
 	w.Write(b)
 }
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ChannelActionUnarchive restores an archived channel.
+	ChannelActionUnarchive = "unarchive"
+	// ChannelActionMove moves a channel to another team.
+	ChannelActionMove = "move"
+	// ChannelActionRename changes the name or display name of a channel.
+	ChannelActionRename = "rename"
+)
+
+// ChannelActionResult describes the outcome of a change to a channel.
+type ChannelActionResult struct {
+	Action string `json:"action"`
+	// Output is what mmctl printed while changing the channel.
+	Output string `json:"output"`
+	// Channel is the channel after the change, if it could be fetched again.
+	Channel *Channel `json:"channel,omitempty"`
+}
+
+// channelRef refers to a channel by ID, or by name within a team.
+type channelRef struct {
+	Team    string
+	Channel string
+}
+
+// arg returns the channel argument understood by mmctl.
+func (ref *channelRef) arg() string {
+	if ref.Team == "" {
+		return ref.Channel
+	}
+
+	return ref.Team + ":" + ref.Channel
+}
+
+// handleUnarchiveWorkspaceChannel responds to POST /api/v1/workspaces/{id}/channels/{channel}/unarchive, restoring
+// an archived channel of the workspace with mmctl.
+func handleUnarchiveWorkspaceChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleWorkspaceChannelAction(c, w, r, ChannelActionUnarchive, func(ref *channelRef) ([]string, *channelRef, error) {
+		return []string{"channel", "unarchive", "--local", ref.arg()}, ref, nil
+	})
+}
+
+// handleMoveWorkspaceChannel responds to POST /api/v1/workspaces/{id}/channels/{channel}/move, moving a channel
+// of the workspace to another team with mmctl.
+func handleMoveWorkspaceChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleWorkspaceChannelAction(c, w, r, ChannelActionMove, func(ref *channelRef) ([]string, *channelRef, error) {
+		moveRequest := &MoveChannelRequest{}
+		err := decodeJSON(moveRequest, r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = moveRequest.Validate()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []string{"channel", "move", "--local", moveRequest.Team, ref.arg()}, &channelRef{Team: moveRequest.Team, Channel: ref.Channel}, nil
+	})
+}
+
+// handleRenameWorkspaceChannel responds to POST /api/v1/workspaces/{id}/channels/{channel}/rename, changing the
+// name or display name of a channel of the workspace with mmctl.
+func handleRenameWorkspaceChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleWorkspaceChannelAction(c, w, r, ChannelActionRename, func(ref *channelRef) ([]string, *channelRef, error) {
+		renameRequest := &RenameChannelRequest{}
+		err := decodeJSON(renameRequest, r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = renameRequest.Validate()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		args := []string{"channel", "rename", "--local", ref.arg()}
+		renamed := &channelRef{Team: ref.Team, Channel: ref.Channel}
+		if renameRequest.Name != nil {
+			args = append(args, "--name", *renameRequest.Name)
+			// Channels given by ID keep their ID when renamed.
+			if ref.Team != "" {
+				renamed.Channel = *renameRequest.Name
+			}
+		}
+		if renameRequest.DisplayName != nil {
+			args = append(args, "--display_name", *renameRequest.DisplayName)
+		}
+
+		return args, renamed, nil
+	})
+}
+
+// handleWorkspaceChannelAction changes the channel given by the request with
+// the mmctl command built by prepare, responding with the changed channel.
+// prepare also returns where the channel can be found after the change, and
+// fails if the request is invalid.
+func handleWorkspaceChannelAction(c *Context, w http.ResponseWriter, r *http.Request, action string, prepare func(*channelRef) ([]string, *channelRef, error)) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	ref := &channelRef{Team: r.URL.Query().Get("team"), Channel: vars["channel"]}
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "channel": ref.arg(), "action": action})
+
+	if !mmctlNamePattern.MatchString(ref.Channel) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid channel %q", ref.Channel))
+		return
+	}
+	if ref.Team != "" && !mmctlNamePattern.MatchString(ref.Team) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid team %q", ref.Team))
+		return
+	}
+
+	args, changed, err := prepare(ref)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	c.Logger.Info("Changing workspace channel")
+
+	output, err := c.CloudClient.ExecClusterInstallationCLI(r.Context(), clusterInstallation.ID, "mmctl", args)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, errors.Wrapf(err, "failed to %s channel: %s", action, strings.TrimSpace(string(output))))
+		return
+	}
+
+	result := &ChannelActionResult{
+		Action: action,
+		Output: strings.TrimSpace(string(output)),
+	}
+
+	result.Channel, err = findChannel(r.Context(), c.CloudClient, clusterInstallation.ID, changed.Team, changed.Channel)
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to fetch channel after change")
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
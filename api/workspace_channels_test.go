@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestWorkspaceChannelActions(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("customer archived a channel by accident", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+	expectWorkspace := func() {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+	}
+	expectMmctl := func(args []string, output string) *gomock.Call {
+		return mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(args)).
+			Times(1).
+			Return([]byte(output), nil)
+	}
+
+	teamList := []string{"team", "list", "--local", "--json"}
+	teamListOutput := `[{"id":"team1","name":"engineering","type":"O"},{"id":"team2","name":"sales","type":"O"}]`
+
+	t.Run("unarchive", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl([]string{"channel", "unarchive", "--local", "engineering:incidents"}, "")
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1"}, `{"id":"channel2","team_id":"team1","name":"incidents","type":"O"}`)
+
+		result, err := client.UnarchiveWorkspaceChannel("installationid", "incidents", "engineering")
+		require.NoError(t, err)
+		assert.Equal(t, ChannelActionUnarchive, result.Action)
+		require.NotNil(t, result.Channel)
+		assert.Equal(t, "channel2", result.Channel.ID)
+		assert.False(t, result.Channel.Archived)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl([]string{"channel", "move", "--local", "sales", "engineering:incidents"}, "")
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team2"}, `{"id":"channel2","team_id":"team2","name":"incidents","type":"O"}`)
+
+		result, err := client.MoveWorkspaceChannel("installationid", "incidents", "engineering", &MoveChannelRequest{Team: "sales"})
+		require.NoError(t, err)
+		assert.Equal(t, ChannelActionMove, result.Action)
+		require.NotNil(t, result.Channel)
+		assert.Equal(t, "team2", result.Channel.TeamID)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		name := "incidents-2020"
+		displayName := "Incidents 2020"
+
+		expectWorkspace()
+		expectMmctl([]string{"channel", "rename", "--local", "engineering:incidents", "--name", name, "--display_name", displayName}, "")
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1"}, `{"id":"channel2","team_id":"team1","name":"incidents-2020","display_name":"Incidents 2020","type":"O"}`)
+
+		result, err := client.RenameWorkspaceChannel("installationid", "incidents", "engineering", &RenameChannelRequest{Name: &name, DisplayName: &displayName})
+		require.NoError(t, err)
+		assert.Equal(t, ChannelActionRename, result.Action)
+		require.NotNil(t, result.Channel)
+		assert.Equal(t, displayName, result.Channel.DisplayName)
+	})
+
+	t.Run("unarchive by ID", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl([]string{"channel", "unarchive", "--local", "channel2"}, "")
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1", "team2"}, `[{"id":"channel1","team_id":"team1","name":"town-square","type":"O"},{"id":"channel2","team_id":"team2","name":"incidents","type":"O"}]`)
+
+		result, err := client.UnarchiveWorkspaceChannel("installationid", "channel2", "")
+		require.NoError(t, err)
+		require.NotNil(t, result.Channel)
+		assert.Equal(t, "incidents", result.Channel.Name)
+	})
+
+	t.Run("channel not found after change", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl([]string{"channel", "unarchive", "--local", "channel2"}, "")
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1", "team2"}, `[{"id":"channel1","team_id":"team1","name":"town-square","type":"O"}]`)
+
+		result, err := client.UnarchiveWorkspaceChannel("installationid", "channel2", "")
+		require.NoError(t, err)
+		assert.Nil(t, result.Channel)
+	})
+
+	t.Run("invalid channel", func(t *testing.T) {
+		result, err := client.UnarchiveWorkspaceChannel("installationid", "--help", "")
+		assert.EqualError(t, err, "failed with status code 400: invalid channel \"--help\"")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid move", func(t *testing.T) {
+		result, err := client.MoveWorkspaceChannel("installationid", "incidents", "engineering", &MoveChannelRequest{})
+		assert.EqualError(t, err, "failed with status code 400: invalid team \"\"")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid rename", func(t *testing.T) {
+		result, err := client.RenameWorkspaceChannel("installationid", "incidents", "engineering", &RenameChannelRequest{})
+		assert.EqualError(t, err, "failed with status code 400: must provide at least one of name or display_name")
+		assert.Nil(t, result)
+	})
+
+	t.Run("error running mmctl", func(t *testing.T) {
+		expectWorkspace()
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return([]byte("channel is not archived"), errors.New("some error"))
+
+		result, err := client.UnarchiveWorkspaceChannel("installationid", "incidents", "engineering")
+		assert.EqualError(t, err, "failed with status code 500: failed to unarchive channel: channel is not archived: some error")
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// WorkspaceConfigChange describes a change made to a single workspace config setting.
+type WorkspaceConfigChange struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
+
+// handlePatchWorkspaceConfig responds to PATCH /api/v1/workspaces/{id}/config, changing a single config setting
+// of the workspace with mmctl. Settings holding secrets, i.e. those redacted
+// when the config is shown, cannot be changed.
+func handlePatchWorkspaceConfig(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	patchRequest := &PatchWorkspaceConfigRequest{}
+	err := decodeJSON(patchRequest, r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	err = patchRequest.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	c.Logger = c.Logger.WithField("key", patchRequest.Key)
+
+	if defaultRedactor.IsRedacted(patchRequest.Key) || c.redactor().IsRedacted(patchRequest.Key) {
+		w.WriteHeader(http.StatusForbidden)
+		c.writeAndLogError(w, errors.Errorf("config key %s holds a secret and cannot be changed through Pillar", patchRequest.Key))
+		return
+	}
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	clusterInstallation, err := getClusterInstallationForWorkspace(r.Context(), c.CloudClient, workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	config, err := getConfigForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	oldValue, ok := lookupConfigValue(config, patchRequest.Key)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("unknown config key %s", patchRequest.Key))
+		return
+	}
+	if _, isObject := oldValue.(map[string]interface{}); isObject {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("config key %s refers to a group of settings, not a single setting", patchRequest.Key))
+		return
+	}
+
+	c.Logger.Info("Setting workspace config value")
+
+	err = setConfigForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID, patchRequest.Key, patchRequest.Value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	config, err = getConfigForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, errors.Wrap(err, "config was changed but could not be fetched again"))
+		return
+	}
+	newValue, _ := lookupConfigValue(config, patchRequest.Key)
+
+	change := &WorkspaceConfigChange{
+		Key:      patchRequest.Key,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}
+
+	b, err := json.Marshal(change)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// setConfigForClusterInstallation runs mmctl config set against the cluster installation.
+func setConfigForClusterInstallation(ctx context.Context, client CloudClient, clusterInstallationID, key string, value interface{}) error {
+	if client == nil {
+		return errors.New("CloudClient is nil")
+	}
+
+	valueArgs, err := configValueArgs(value)
+	if err != nil {
+		return err
+	}
+
+	// The separator keeps values starting with a dash from being parsed as flags.
+	args := append([]string{"config", "set", "--local", "--", key}, valueArgs...)
+	output, err := client.ExecClusterInstallationCLI(ctx, clusterInstallationID, "mmctl", args)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set config: %s", strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// lookupConfigValue returns the value at the given dot separated config path.
+func lookupConfigValue(config map[string]interface{}, key string) (interface{}, bool) {
+	var value interface{} = config
+	for _, k := range strings.Split(key, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[k]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// configValueArgs converts a JSON config value into the arguments expected by mmctl config set.
+func configValueArgs(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, errors.New("value must be provided")
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case []interface{}:
+		var args []string
+		for _, element := range v {
+			if _, isList := element.([]interface{}); isList {
+				return nil, errors.New("nested lists are not supported")
+			}
+			elementArgs, err := configValueArgs(element)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, elementArgs...)
+		}
+		if len(args) == 0 {
+			return nil, errors.New("list values must not be empty")
+		}
+		return args, nil
+	default:
+		return nil, errors.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupConfigValue(t *testing.T) {
+	config := makeTestConfig(t)
+
+	value, ok := lookupConfigValue(config, "ServiceSettings.SiteURL")
+	assert.True(t, ok)
+	assert.Equal(t, "https://joram.cloud.mattermost.com", value)
+
+	value, ok = lookupConfigValue(config, "SqlSettings")
+	assert.True(t, ok)
+	assert.IsType(t, map[string]interface{}{}, value)
+
+	_, ok = lookupConfigValue(config, "ServiceSettings.Unknown")
+	assert.False(t, ok)
+
+	_, ok = lookupConfigValue(config, "ServiceSettings.SiteURL.Nested")
+	assert.False(t, ok)
+}
+
+func TestConfigValueArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       interface{}
+		expected    []string
+	}{
+		{"string", "value", []string{"value"}},
+		{"empty string", "", []string{""}},
+		{"bool", true, []string{"true"}},
+		{"integer", float64(20), []string{"20"}},
+		{"decimal", 1.5, []string{"1.5"}},
+		{"list", []interface{}{"a", "b"}, []string{"a", "b"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			args, err := configValueArgs(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, args)
+		})
+	}
+
+	invalidValues := map[string]interface{}{
+		"nil":         nil,
+		"object":      map[string]interface{}{"key": "value"},
+		"empty list":  []interface{}{},
+		"nested list": []interface{}{[]interface{}{"a"}},
+	}
+
+	for description, value := range invalidValues {
+		t.Run(description, func(t *testing.T) {
+			_, err := configValueArgs(value)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// handleDeleteWorkspace responds to DELETE /api/v1/workspaces/{id}, requesting the deletion of a workspace.
+//
+// The request must echo the DNS of the workspace, and Cloud Enterprise workspaces,
+// as told by their license or else their isolated affinity, may only be deleted
+// with the workspace:delete:enterprise permission. The workspace is returned in
+// its resulting deletion pending state.
+func handleDeleteWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	deleteRequest := &DeleteWorkspaceRequest{}
+	err := decodeJSON(deleteRequest, r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	err = deleteRequest.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(deleteRequest.ConfirmDNS), installation.DNS) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.New("confirmation does not match the workspace DNS"))
+		return
+	}
+	isEnterprise := installationEdition(installation) == WorkspaceEditionEnterprise || installation.Affinity == cloud.InstallationAffinityIsolated
+	if isEnterprise && !c.can(PermissionWorkspaceDeleteEnterprise) {
+		w.WriteHeader(http.StatusForbidden)
+		c.writeAndLogError(w, errors.Errorf("deleting %s workspaces requires the %s permission", WorkspaceEditionEnterprise, PermissionWorkspaceDeleteEnterprise))
+		return
+	}
+	if !installation.ValidTransitionState(cloud.InstallationStateDeletionRequested) {
+		w.WriteHeader(http.StatusConflict)
+		c.writeAndLogError(w, errors.Errorf("cannot delete a workspace in state %s", installation.State))
+		return
+	}
+
+	c.Logger.WithField("dns", installation.DNS).Warn("Deleting workspace")
+
+	err = c.CloudClient.DeleteInstallation(r.Context(), workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	// The provisioner does not return the installation when deleting it, so
+	// it is fetched again to report the deletion pending state. Should that
+	// fail, the deletion was still requested and the state is reported as such.
+	deleted, err := c.CloudClient.GetInstallation(withCacheRefresh(r.Context()), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to fetch deleted workspace")
+	}
+	if deleted != nil {
+		installation = deleted
+	} else {
+		installation.State = cloud.InstallationStateDeletionRequested
+	}
+
+	b, err := json.Marshal(convertInstallationToWorkspace(installation, c.redactor()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(b)
+}
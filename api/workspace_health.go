@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+const (
+	// HealthStatusPass means a check found nothing wrong.
+	HealthStatusPass = "pass"
+	// HealthStatusWarn means a check found something that may need attention.
+	HealthStatusWarn = "warn"
+	// HealthStatusFail means a check found the workspace to be broken.
+	HealthStatusFail = "fail"
+)
+
+const (
+	// HealthCheckInstallation checks the state of the installation in the provisioner.
+	HealthCheckInstallation = "installation"
+	// HealthCheckClusterInstallation checks the state of the cluster installations.
+	HealthCheckClusterInstallation = "cluster_installation"
+	// HealthCheckServer checks the status reported by mmctl system status.
+	HealthCheckServer = "server"
+	// HealthCheckDatabase checks the database connectivity reported by the server.
+	HealthCheckDatabase = "database"
+	// HealthCheckFilestore checks the file store connectivity reported by the server.
+	HealthCheckFilestore = "filestore"
+	// HealthCheckPing checks that the server answers pings at its public DNS.
+	HealthCheckPing = "ping"
+)
+
+// workspacePingTimeout limits how long the server of a workspace is pinged for.
+const workspacePingTimeout = 10 * time.Second
+
+// defaultWorkspaceHTTPClient is used to reach workspaces when the context configures no other client.
+var defaultWorkspaceHTTPClient = &http.Client{Timeout: workspacePingTimeout}
+
+// serverStatusSubcommand is the mmctl subcommand printing the status of the server of a cluster installation.
+var serverStatusSubcommand = []string{"system", "status", "--local", "--json"}
+
+// HealthCheck is the outcome of a single check of a workspace.
+type HealthCheck struct {
+	Name string `json:"name"`
+	// Status is one of pass, warn or fail.
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// WorkspaceHealth is a report of the checks run against a workspace.
+type WorkspaceHealth struct {
+	WorkspaceID string `json:"workspace_id"`
+	// Status is the worst status of any check.
+	Status string         `json:"status"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// add records a check, updating the overall status.
+func (h *WorkspaceHealth) add(name, status, message string) {
+	h.Checks = append(h.Checks, &HealthCheck{Name: name, Status: status, Message: message})
+
+	if healthStatusSeverity(status) > healthStatusSeverity(h.Status) {
+		h.Status = status
+	}
+}
+
+func healthStatusSeverity(status string) int {
+	switch status {
+	case HealthStatusFail:
+		return 2
+	case HealthStatusWarn:
+		return 1
+	}
+
+	return 0
+}
+
+// serverStatus holds the status fields reported by the server, both through
+// mmctl and its ping endpoint.
+type serverStatus struct {
+	Status          string `json:"status"`
+	DatabaseStatus  string `json:"database_status"`
+	FilestoreStatus string `json:"filestore_status"`
+}
+
+// handleGetWorkspaceHealth responds to GET /api/v1/workspaces/{id}/health, running every health check against the
+// workspace and reporting whether each passed.
+//
+// The report is returned even when checks fail; only a missing workspace or a failure to reach the provisioner is
+// reported as an error.
+func handleGetWorkspaceHealth(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	health := &WorkspaceHealth{WorkspaceID: workspaceID, Status: HealthStatusPass}
+	health.add(checkInstallationState(installation.State))
+
+	clusterInstallations, err := getClusterInstallationsForWorkspace(r.Context(), c.CloudClient, workspaceID)
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to get workspace cluster installations")
+		health.add(HealthCheckClusterInstallation, HealthStatusFail, fmt.Sprintf("failed to get cluster installations: %s", err))
+	} else {
+		health.add(checkClusterInstallationStates(clusterInstallations))
+	}
+
+	checkServerStatus(r.Context(), c.CloudClient, clusterInstallations, health)
+
+	health.add(pingWorkspace(r.Context(), c.workspaceHTTPClient(), installation.DNS))
+
+	b, err := json.Marshal(health)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// checkInstallationState checks the state of the installation in the provisioner.
+func checkInstallationState(state string) (string, string, string) {
+	message := fmt.Sprintf("installation is %s", state)
+
+	switch {
+	case state == cloud.InstallationStateStable:
+		return HealthCheckInstallation, HealthStatusPass, message
+	case strings.HasSuffix(state, "-failed"), state == cloud.InstallationStateCreationNoCompatibleClusters:
+		return HealthCheckInstallation, HealthStatusFail, message
+	case strings.HasPrefix(state, "deletion-"), state == cloud.InstallationStateDeleted:
+		return HealthCheckInstallation, HealthStatusFail, message
+	}
+
+	return HealthCheckInstallation, HealthStatusWarn, message
+}
+
+// checkClusterInstallationStates checks that a cluster installation of the workspace is stable.
+func checkClusterInstallationStates(clusterInstallations []*cloud.ClusterInstallation) (string, string, string) {
+	if len(clusterInstallations) == 0 {
+		return HealthCheckClusterInstallation, HealthStatusFail, "workspace does not have a cluster installation"
+	}
+
+	states := []string{}
+	status := HealthStatusWarn
+	for _, clusterInstallation := range clusterInstallations {
+		states = append(states, fmt.Sprintf("%s is %s", clusterInstallation.ID, clusterInstallation.State))
+
+		switch clusterInstallation.State {
+		case cloud.ClusterInstallationStateStable:
+			status = HealthStatusPass
+		case cloud.ClusterInstallationStateCreationFailed, cloud.ClusterInstallationStateDeletionFailed:
+			if status != HealthStatusPass {
+				status = HealthStatusFail
+			}
+		}
+	}
+
+	return HealthCheckClusterInstallation, status, strings.Join(states, ", ")
+}
+
+// checkServerStatus asks the server of the workspace for its status with
+// mmctl, checking the server itself along with its database and file store.
+func checkServerStatus(ctx context.Context, client CloudClient, clusterInstallations []*cloud.ClusterInstallation, health *WorkspaceHealth) {
+	failAll := func(message string) {
+		health.add(HealthCheckServer, HealthStatusFail, message)
+		health.add(HealthCheckDatabase, HealthStatusFail, message)
+		health.add(HealthCheckFilestore, HealthStatusFail, message)
+	}
+
+	clusterInstallation, err := selectClusterInstallation(clusterInstallations)
+	if err != nil {
+		failAll(err.Error())
+		return
+	}
+
+	values, err := execMmctlJSON(ctx, client, clusterInstallation.ID, serverStatusSubcommand)
+	if err != nil {
+		failAll(fmt.Sprintf("failed to get server status: %s", err))
+		return
+	}
+	if len(values) == 0 {
+		failAll("server did not report its status")
+		return
+	}
+
+	status := &serverStatus{}
+	err = json.Unmarshal(values[0], status)
+	if err != nil {
+		failAll(fmt.Sprintf("failed to decode server status: %s", err))
+		return
+	}
+
+	health.add(checkReportedStatus(HealthCheckServer, "server", status.Status))
+	health.add(checkReportedStatus(HealthCheckDatabase, "database", status.DatabaseStatus))
+	health.add(checkReportedStatus(HealthCheckFilestore, "file store", status.FilestoreStatus))
+}
+
+// checkReportedStatus checks a status reported by the server, which is OK when healthy.
+func checkReportedStatus(name, subject, reported string) (string, string, string) {
+	switch reported {
+	case "OK":
+		return name, HealthStatusPass, fmt.Sprintf("%s is OK", subject)
+	case "":
+		return name, HealthStatusWarn, fmt.Sprintf("server did not report the status of the %s", subject)
+	}
+
+	return name, HealthStatusFail, fmt.Sprintf("%s is %s", subject, reported)
+}
+
+// pingWorkspace pings the server of the workspace at its public DNS.
+func pingWorkspace(ctx context.Context, client *http.Client, dns string) (string, string, string) {
+	if dns == "" {
+		return HealthCheckPing, HealthStatusFail, "workspace has no DNS"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, workspacePingTimeout)
+	defer cancel()
+
+	u := fmt.Sprintf("https://%s/api/v4/system/ping", dns)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return HealthCheckPing, HealthStatusFail, err.Error()
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return HealthCheckPing, HealthStatusFail, errors.Wrapf(err, "failed to ping %s", u).Error()
+	}
+	defer closeBody(resp)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if resp.StatusCode != http.StatusOK {
+		return HealthCheckPing, HealthStatusFail, fmt.Sprintf("%s responded with status code %d", u, resp.StatusCode)
+	}
+
+	ping := &serverStatus{}
+	err = decodeJSON(ping, resp.Body)
+	if err != nil {
+		return HealthCheckPing, HealthStatusFail, errors.Wrapf(err, "failed to decode ping response of %s", u).Error()
+	}
+	if ping.Status != "OK" {
+		return HealthCheckPing, HealthStatusFail, fmt.Sprintf("%s responded with status %q", u, ping.Status)
+	}
+
+	return HealthCheckPing, HealthStatusPass, fmt.Sprintf("%s responded in %s", u, elapsed)
+}
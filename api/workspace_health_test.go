@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+// newTestWorkspaceServer starts a stand-in for the server of a workspace,
+// answering pings with the given status code and body.
+func newTestWorkspaceServer(t *testing.T, statusCode int, body string) *httptest.Server {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/system/ping", r.URL.Path)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestPingWorkspace(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := newTestWorkspaceServer(t, http.StatusOK, `{"status":"OK"}`)
+
+		name, status, message := pingWorkspace(context.Background(), ts.Client(), ts.Listener.Addr().String())
+		assert.Equal(t, HealthCheckPing, name)
+		assert.Equal(t, HealthStatusPass, status, message)
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		ts := newTestWorkspaceServer(t, http.StatusOK, `{"status":"UNHEALTHY"}`)
+
+		_, status, message := pingWorkspace(context.Background(), ts.Client(), ts.Listener.Addr().String())
+		assert.Equal(t, HealthStatusFail, status)
+		assert.Contains(t, message, "UNHEALTHY")
+	})
+
+	t.Run("error status code", func(t *testing.T) {
+		ts := newTestWorkspaceServer(t, http.StatusBadGateway, "")
+
+		_, status, message := pingWorkspace(context.Background(), ts.Client(), ts.Listener.Addr().String())
+		assert.Equal(t, HealthStatusFail, status)
+		assert.Contains(t, message, "502")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		ts := newTestWorkspaceServer(t, http.StatusOK, `{"status":"OK"}`)
+		addr := ts.Listener.Addr().String()
+		ts.Close()
+
+		_, status, _ := pingWorkspace(context.Background(), ts.Client(), addr)
+		assert.Equal(t, HealthStatusFail, status)
+	})
+
+	t.Run("no dns", func(t *testing.T) {
+		_, status, _ := pingWorkspace(context.Background(), http.DefaultClient, "")
+		assert.Equal(t, HealthStatusFail, status)
+	})
+}
+
+func TestCheckInstallationState(t *testing.T) {
+	testCases := map[string]string{
+		cloud.InstallationStateStable:                       HealthStatusPass,
+		cloud.InstallationStateUpdateInProgress:             HealthStatusWarn,
+		cloud.InstallationStateHibernating:                  HealthStatusWarn,
+		cloud.InstallationStateUpdateFailed:                 HealthStatusFail,
+		cloud.InstallationStateDeletionRequested:            HealthStatusFail,
+		cloud.InstallationStateCreationNoCompatibleClusters: HealthStatusFail,
+	}
+
+	for state, expected := range testCases {
+		_, status, _ := checkInstallationState(state)
+		assert.Equal(t, expected, status, state)
+	}
+}
+
+func TestWorkspaceHealth(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	workspaceServer := newTestWorkspaceServer(t, http.StatusOK, `{"status":"OK"}`)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:              logger,
+		CloudClient:         mockCloudClient,
+		WorkspaceHTTPClient: workspaceServer.Client(),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("workspace is down", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{
+		ID:    "installationid",
+		DNS:   workspaceServer.Listener.Addr().String(),
+		State: cloud.InstallationStateStable,
+	}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+
+	t.Run("healthy", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(serverStatusSubcommand)).
+			Times(1).
+			Return([]byte(`{"status":"OK","database_status":"OK","filestore_status":"OK"}`), nil)
+
+		health, err := client.GetWorkspaceHealth("installationid")
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusPass, health.Status)
+		require.Len(t, health.Checks, 6)
+		for i, name := range []string{HealthCheckInstallation, HealthCheckClusterInstallation, HealthCheckServer, HealthCheckDatabase, HealthCheckFilestore, HealthCheckPing} {
+			assert.Equal(t, name, health.Checks[i].Name)
+			assert.Equal(t, HealthStatusPass, health.Checks[i].Status, health.Checks[i].Message)
+		}
+	})
+
+	t.Run("database unavailable", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(1).
+			Return([]byte(`{"status":"UNHEALTHY","database_status":"UNHEALTHY"}`), nil)
+
+		health, err := client.GetWorkspaceHealth("installationid")
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusFail, health.Status)
+		require.Len(t, health.Checks, 6)
+		assert.Equal(t, HealthStatusFail, health.Checks[2].Status)
+		assert.Equal(t, HealthStatusFail, health.Checks[3].Status)
+		assert.Equal(t, HealthStatusWarn, health.Checks[4].Status)
+		assert.Equal(t, HealthStatusPass, health.Checks[5].Status)
+	})
+
+	t.Run("no cluster installation", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		health, err := client.GetWorkspaceHealth("installationid")
+		require.NoError(t, err)
+		assert.Equal(t, HealthStatusFail, health.Status)
+		assert.Equal(t, HealthStatusFail, health.Checks[1].Status)
+		assert.Contains(t, health.Checks[2].Message, "cluster installation")
+	})
+
+	t.Run("workspace not found", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("missing"), gomock.Any()).Times(1).Return(nil, nil)
+
+		health, err := client.GetWorkspaceHealth("missing")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, health)
+	})
+}
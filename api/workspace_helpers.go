@@ -1,49 +1,100 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"net/http"
+
+	"github.com/pkg/errors"
 
 	cloud "github.com/mattermost/mattermost-cloud/model"
 )
 
-func convertInstallationToWorkspace(installation *cloud.InstallationDTO) *Workspace {
+// convertInstallationToWorkspace converts an installation to a workspace,
+// redacting sensitive environment variables with the given redactor.
+func convertInstallationToWorkspace(installation *cloud.InstallationDTO, redactor *Redactor) *Workspace {
 	if installation == nil {
 		return nil
 	}
 
-	edition := WorkspaceEditionProfessional
-	if installation.Affinity == cloud.InstallationAffinityIsolated {
-		edition = WorkspaceEditionEnterprise
-	}
-
 	groupID := ""
 	if installation.GroupID != nil {
 		groupID = *installation.GroupID
 	}
 
-	return &Workspace{
-		ID:        installation.ID,
-		GroupID:   groupID,
-		Version:   installation.Version,
-		DNS:       installation.DNS,
-		Size:      installation.Size,
-		Database:  installation.Database,
-		Filestore: installation.Filestore,
-		CreateAt:  installation.CreateAt,
-		DeleteAt:  installation.DeleteAt,
-		Edition:   edition,
+	lockAcquiredBy := ""
+	if installation.LockAcquiredBy != nil {
+		lockAcquiredBy = *installation.LockAcquiredBy
 	}
+
+	workspace := &Workspace{
+		ID:             installation.ID,
+		GroupID:        groupID,
+		State:          installation.State,
+		Version:        installation.Version,
+		Image:          installation.Image,
+		DNS:            installation.DNS,
+		Size:           installation.Size,
+		Affinity:       installation.Affinity,
+		Database:       installation.Database,
+		Filestore:      installation.Filestore,
+		HasLicense:     installation.License != "",
+		MattermostEnv:  redactor.RedactEnv(installation.MattermostEnv),
+		EnvRedacted:    true,
+		LockAcquiredBy: lockAcquiredBy,
+		LockAcquiredAt: installation.LockAcquiredAt,
+		CreateAt:       installation.CreateAt,
+		DeleteAt:       installation.DeleteAt,
+	}
+	setWorkspaceLicense(workspace, installation.License)
+
+	return workspace
 }
 
-func convertInstallationsToWorkspaces(installations []*cloud.InstallationDTO) []*Workspace {
+// setWorkspaceLicense sets the edition and license details of a workspace
+// from its license. Workspaces without a license are on the free edition.
+// Should the license not be readable or its SKU be unknown, the edition is
+// guessed from the affinity instead, as isolated workspaces are Enterprise.
+func setWorkspaceLicense(workspace *Workspace, encodedLicense string) {
+	if encodedLicense == "" {
+		workspace.Edition = WorkspaceEditionFree
+		return
+	}
+
+	license, err := parseLicense(encodedLicense)
+	if err == nil {
+		workspace.LicenseID = license.ID
+		workspace.LicenseSKU = license.SkuName
+		workspace.LicenseSeats = license.seats()
+		workspace.LicenseExpiresAt = license.ExpiresAt
+		workspace.Edition = license.edition()
+	}
+
+	if workspace.Edition == "" {
+		workspace.Edition = WorkspaceEditionProfessional
+		if workspace.Affinity == cloud.InstallationAffinityIsolated {
+			workspace.Edition = WorkspaceEditionEnterprise
+		}
+	}
+}
+
+// installationEdition returns the edition of the installation as told by its license.
+func installationEdition(installation *cloud.InstallationDTO) string {
+	workspace := &Workspace{Affinity: installation.Affinity}
+	setWorkspaceLicense(workspace, installation.License)
+
+	return workspace.Edition
+}
+
+func convertInstallationsToWorkspaces(installations []*cloud.InstallationDTO, redactor *Redactor) []*Workspace {
 	if installations == nil {
 		return nil
 	}
 
 	workspaces := make([]*Workspace, len(installations))
 	for index, installation := range installations {
-		workspaces[index] = convertInstallationToWorkspace(installation)
+		workspaces[index] = convertInstallationToWorkspace(installation, redactor)
 	}
 	return workspaces
 }
@@ -57,15 +108,206 @@ func convertCloudGroupToGroup(cloudGroup *cloud.Group) *Group {
 		ID:          cloudGroup.ID,
 		Name:        cloudGroup.Name,
 		Description: cloudGroup.Description,
+		Version:     cloudGroup.Version,
+		Image:       cloudGroup.Image,
+		MaxRolling:  cloudGroup.MaxRolling,
+		CreateAt:    cloudGroup.CreateAt,
+		DeleteAt:    cloudGroup.DeleteAt,
+	}
+}
+
+func convertCloudGroupsToGroups(cloudGroups []*cloud.Group) []*Group {
+	if cloudGroups == nil {
+		return nil
+	}
+
+	groups := make([]*Group, len(cloudGroups))
+	for index, cloudGroup := range cloudGroups {
+		groups[index] = convertCloudGroupToGroup(cloudGroup)
+	}
+	return groups
+}
+
+func convertCloudClusterToCluster(cloudCluster *cloud.ClusterDTO) *Cluster {
+	if cloudCluster == nil || cloudCluster.Cluster == nil {
+		return nil
+	}
+
+	cluster := &Cluster{
+		ID:                 cloudCluster.ID,
+		State:              cloudCluster.State,
+		Provider:           cloudCluster.Provider,
+		Provisioner:        cloudCluster.Provisioner,
+		AllowInstallations: cloudCluster.AllowInstallations,
+		CreateAt:           cloudCluster.CreateAt,
+		DeleteAt:           cloudCluster.DeleteAt,
+		Annotations:        []string{},
+	}
+	if cloudCluster.ProvisionerMetadataKops != nil {
+		cluster.Version = cloudCluster.ProvisionerMetadataKops.Version
+	}
+	for _, annotation := range cloudCluster.Annotations {
+		cluster.Annotations = append(cluster.Annotations, annotation.Name)
+	}
+
+	return cluster
+}
+
+func convertCloudClustersToClusters(cloudClusters []*cloud.ClusterDTO) []*Cluster {
+	if cloudClusters == nil {
+		return nil
+	}
+
+	clusters := make([]*Cluster, len(cloudClusters))
+	for index, cloudCluster := range cloudClusters {
+		clusters[index] = convertCloudClusterToCluster(cloudCluster)
+	}
+	return clusters
+}
+
+func convertCloudClusterInstallationToClusterInstallation(cloudClusterInstallation *cloud.ClusterInstallation) *ClusterInstallation {
+	if cloudClusterInstallation == nil {
+		return nil
+	}
+
+	return &ClusterInstallation{
+		ID:          cloudClusterInstallation.ID,
+		ClusterID:   cloudClusterInstallation.ClusterID,
+		WorkspaceID: cloudClusterInstallation.InstallationID,
+		Namespace:   cloudClusterInstallation.Namespace,
+		State:       cloudClusterInstallation.State,
+		CreateAt:    cloudClusterInstallation.CreateAt,
+		DeleteAt:    cloudClusterInstallation.DeleteAt,
+	}
+}
+
+func convertCloudClusterInstallationsToClusterInstallations(cloudClusterInstallations []*cloud.ClusterInstallation) []*ClusterInstallation {
+	if cloudClusterInstallations == nil {
+		return nil
+	}
+
+	clusterInstallations := make([]*ClusterInstallation, len(cloudClusterInstallations))
+	for index, cloudClusterInstallation := range cloudClusterInstallations {
+		clusterInstallations[index] = convertCloudClusterInstallationToClusterInstallation(cloudClusterInstallation)
+	}
+	return clusterInstallations
+}
+
+// getClusterInstallationForWorkspace returns the cluster installation of the
+// given workspace that commands should be run against.
+func getClusterInstallationForWorkspace(ctx context.Context, client CloudClient, workspaceID string) (*cloud.ClusterInstallation, error) {
+	clusterInstallations, err := getClusterInstallationsForWorkspace(ctx, client, workspaceID)
+	if err != nil {
+		return nil, err
 	}
+
+	return selectClusterInstallation(clusterInstallations)
 }
 
-func getConfigForClusterInstallation(client CloudClient, clusterInstallationID string) (map[string]interface{}, error) {
+// getClusterInstallationsForWorkspace returns every cluster installation of the given workspace.
+func getClusterInstallationsForWorkspace(ctx context.Context, client CloudClient, workspaceID string) ([]*cloud.ClusterInstallation, error) {
+	if client == nil {
+		return nil, errors.New("CloudClient is nil")
+	}
+
+	return client.GetClusterInstallations(ctx, &cloud.GetClusterInstallationsRequest{InstallationID: workspaceID, PerPage: 1000})
+}
+
+// getWorkspaceClusterInstallation returns the cluster installation of the
+// workspace that commands should be run against, responding with the
+// appropriate error and returning false if there is none.
+func getWorkspaceClusterInstallation(c *Context, w http.ResponseWriter, r *http.Request, workspaceID string) (*cloud.ClusterInstallation, bool) {
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return nil, false
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	clusterInstallation, err := getClusterInstallationForWorkspace(r.Context(), c.CloudClient, workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return nil, false
+	}
+
+	return clusterInstallation, true
+}
+
+// selectClusterInstallation picks the cluster installation that commands
+// should be run against, preferring one in a stable state.
+func selectClusterInstallation(clusterInstallations []*cloud.ClusterInstallation) (*cloud.ClusterInstallation, error) {
+	if len(clusterInstallations) == 0 {
+		return nil, errors.New("workspace does not have a cluster installation")
+	}
+
+	for _, clusterInstallation := range clusterInstallations {
+		if clusterInstallation.State == cloud.ClusterInstallationStateStable {
+			return clusterInstallation, nil
+		}
+	}
+
+	return clusterInstallations[0], nil
+}
+
+// execMmctlJSON runs an mmctl command with JSON output against the cluster
+// installation, returning the values it printed.
+func execMmctlJSON(ctx context.Context, client CloudClient, clusterInstallationID string, args []string) ([]json.RawMessage, error) {
+	output, err := client.ExecClusterInstallationCLI(ctx, clusterInstallationID, "mmctl", args)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMmctlJSON(output)
+}
+
+// decodeMmctlJSON decodes the output of an mmctl command run with --json into
+// its values. Depending on the command, mmctl prints either an array or one
+// object per result, possibly between plain text messages such as users that
+// could not be found, which are skipped.
+func decodeMmctlJSON(output []byte) ([]json.RawMessage, error) {
+	values := []json.RawMessage{}
+	for {
+		start := bytes.IndexAny(output, "{[")
+		if start < 0 {
+			return values, nil
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(output[start:]))
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode mmctl output")
+		}
+		output = output[start+int(decoder.InputOffset()):]
+
+		if value[0] != '[' {
+			values = append(values, value)
+			continue
+		}
+
+		var items []json.RawMessage
+		err = json.Unmarshal(value, &items)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode mmctl output")
+		}
+		values = append(values, items...)
+	}
+}
+
+// configShowSubcommand is the mmctl subcommand printing the config of a cluster installation.
+var configShowSubcommand = []string{"config", "show", "--local"}
+
+func getConfigForClusterInstallation(ctx context.Context, client CloudClient, clusterInstallationID string) (map[string]interface{}, error) {
 	if client == nil {
 		return nil, errors.New("CloudClient is nil")
 	}
 
-	cmdOutput, err := client.ExecClusterInstallationCLI(clusterInstallationID, "mmctl", []string{"config", "show", "--local"})
+	cmdOutput, err := client.ExecClusterInstallationCLI(ctx, clusterInstallationID, "mmctl", configShowSubcommand)
 	if err != nil {
 		return nil, err
 	}
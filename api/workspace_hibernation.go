@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// handleHibernateWorkspace responds to POST /api/v1/workspaces/{id}/hibernate, hibernating a stable workspace.
+func handleHibernateWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleWorkspaceHibernation(c, w, r, "hibernate", func(installation *cloud.InstallationDTO) bool {
+		return installation.ValidTransitionState(cloud.InstallationStateHibernationRequested)
+	}, c.CloudClient.HibernateInstallation)
+}
+
+// handleWakeupWorkspace responds to POST /api/v1/workspaces/{id}/wakeup, waking a hibernating workspace.
+func handleWakeupWorkspace(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleWorkspaceHibernation(c, w, r, "wake up", func(installation *cloud.InstallationDTO) bool {
+		return installation.State == cloud.InstallationStateHibernating
+	}, c.CloudClient.WakeupInstallation)
+}
+
+// handleWorkspaceHibernation checks that the workspace is in a state allowing
+// the action before requesting it, responding with the resulting workspace.
+func handleWorkspaceHibernation(c *Context, w http.ResponseWriter, r *http.Request, action string, allowed func(*cloud.InstallationDTO) bool, request func(context.Context, string) (*cloud.InstallationDTO, error)) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !allowed(installation) {
+		w.WriteHeader(http.StatusConflict)
+		c.writeAndLogError(w, errors.Errorf("cannot %s a workspace in state %s", action, installation.State))
+		return
+	}
+
+	c.Logger.WithField("state", installation.State).Infof("Requesting workspace %s", action)
+
+	installation, err = request(r.Context(), workspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(convertInstallationToWorkspace(installation, c.redactor()))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(b)
+}
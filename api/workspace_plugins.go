@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Plugin is a plugin installed on a workspace.
+type Plugin struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// mmctlPlugins holds the plugins printed by mmctl, split by whether they are enabled.
+type mmctlPlugins struct {
+	Active   []*mmctlPlugin `json:"active"`
+	Inactive []*mmctlPlugin `json:"inactive"`
+}
+
+// mmctlPlugin holds the fields of a plugin manifest printed by mmctl.
+type mmctlPlugin struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// pluginListSubcommand is the mmctl subcommand listing the plugins of a cluster installation.
+var pluginListSubcommand = []string{"plugin", "list", "--local", "--json"}
+
+// pluginActionCommands are the mmctl commands run for each action, given the
+// ID of the plugin and the requested version.
+var pluginActionCommands = map[string]func(pluginID, version string) []string{
+	PluginActionEnable: func(pluginID, version string) []string {
+		return []string{"plugin", "enable", "--local", pluginID}
+	},
+	PluginActionDisable: func(pluginID, version string) []string {
+		return []string{"plugin", "disable", "--local", pluginID}
+	},
+	PluginActionInstall: func(pluginID, version string) []string {
+		args := []string{"plugin", "marketplace", "install", "--local", pluginID}
+		if version != "" {
+			args = append(args, version)
+		}
+		return args
+	},
+}
+
+// PluginActionResult describes the outcome of an action on a workspace plugin.
+type PluginActionResult struct {
+	Action string `json:"action"`
+	// Output is what mmctl printed while running the action.
+	Output string `json:"output"`
+	// Plugin is the plugin after the action, if it could be fetched again.
+	Plugin *Plugin `json:"plugin,omitempty"`
+}
+
+// handleListWorkspacePlugins responds to GET /api/v1/workspaces/{id}/plugins, listing the enabled and disabled
+// plugins of the workspace with mmctl.
+func handleListWorkspacePlugins(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	plugins, err := getPluginsForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(plugins)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleWorkspacePluginAction responds to POST /api/v1/workspaces/{id}/plugins/{plugin}/actions, enabling,
+// disabling or installing a plugin of the workspace with mmctl.
+func handleWorkspacePluginAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	pluginID := vars["plugin"]
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "plugin": pluginID})
+
+	if !pluginIDPattern.MatchString(pluginID) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid plugin ID %q", pluginID))
+		return
+	}
+
+	actionRequest := &PluginActionRequest{}
+	err := decodeJSON(actionRequest, r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	err = actionRequest.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	c.Logger = c.Logger.WithField("action", actionRequest.Action)
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	// Only installed plugins can be enabled or disabled.
+	if actionRequest.Action != PluginActionInstall {
+		var plugins []*Plugin
+		plugins, err = getPluginsForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			c.writeAndLogError(w, err)
+			return
+		}
+		if findPlugin(plugins, pluginID) == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	}
+
+	c.Logger.Info("Running plugin action")
+
+	args := pluginActionCommands[actionRequest.Action](pluginID, actionRequest.Version)
+	output, err := c.CloudClient.ExecClusterInstallationCLI(r.Context(), clusterInstallation.ID, "mmctl", args)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, errors.Wrapf(err, "failed to %s plugin: %s", actionRequest.Action, strings.TrimSpace(string(output))))
+		return
+	}
+
+	result := &PluginActionResult{
+		Action: actionRequest.Action,
+		Output: strings.TrimSpace(string(output)),
+	}
+
+	plugins, err := getPluginsForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID)
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to fetch plugin after action")
+	} else {
+		result.Plugin = findPlugin(plugins, pluginID)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// findPlugin returns the plugin with the given ID, or nil if there is none.
+func findPlugin(plugins []*Plugin, pluginID string) *Plugin {
+	for _, plugin := range plugins {
+		if plugin.ID == pluginID {
+			return plugin
+		}
+	}
+
+	return nil
+}
+
+// getPluginsForClusterInstallation lists the enabled and then the disabled
+// plugins of the cluster installation with mmctl.
+func getPluginsForClusterInstallation(ctx context.Context, client CloudClient, clusterInstallationID string) ([]*Plugin, error) {
+	values, err := execMmctlJSON(ctx, client, clusterInstallationID, pluginListSubcommand)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := []*Plugin{}
+	for _, value := range values {
+		response := &mmctlPlugins{}
+		err = json.Unmarshal(value, response)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode plugins")
+		}
+
+		for _, plugin := range response.Active {
+			plugins = append(plugins, convertMmctlPlugin(plugin, true))
+		}
+		for _, plugin := range response.Inactive {
+			plugins = append(plugins, convertMmctlPlugin(plugin, false))
+		}
+	}
+
+	return plugins, nil
+}
+
+func convertMmctlPlugin(plugin *mmctlPlugin, enabled bool) *Plugin {
+	return &Plugin{
+		ID:          plugin.ID,
+		Name:        plugin.Name,
+		Description: plugin.Description,
+		Version:     plugin.Version,
+		Enabled:     enabled,
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+const testPluginList = `{"active":[{"id":"com.mattermost.nps","name":"User Satisfaction Surveys","version":"1.1.0"}],"inactive":[{"id":"jira","name":"Jira","description":"Atlassian Jira plugin","version":"3.0.1"}]}`
+
+func TestWorkspacePlugins(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("plugin release is crashing", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+	expectWorkspace := func() {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+	}
+	expectMmctl := func(args []string, output string) *gomock.Call {
+		return mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(args)).
+			Times(1).
+			Return([]byte(output), nil)
+	}
+
+	t.Run("list plugins", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(pluginListSubcommand, testPluginList)
+
+		plugins, err := client.ListWorkspacePlugins("installationid")
+		require.NoError(t, err)
+		assert.Equal(t, []*Plugin{
+			{ID: "com.mattermost.nps", Name: "User Satisfaction Surveys", Version: "1.1.0", Enabled: true},
+			{ID: "jira", Name: "Jira", Description: "Atlassian Jira plugin", Version: "3.0.1", Enabled: false},
+		}, plugins)
+	})
+
+	t.Run("disable plugin", func(t *testing.T) {
+		expectWorkspace()
+		gomock.InOrder(
+			expectMmctl(pluginListSubcommand, testPluginList),
+			expectMmctl([]string{"plugin", "disable", "--local", "com.mattermost.nps"}, "Plugin com.mattermost.nps disabled\n"),
+			expectMmctl(pluginListSubcommand, `{"active":[],"inactive":[{"id":"com.mattermost.nps","version":"1.1.0"},{"id":"jira","version":"3.0.1"}]}`),
+		)
+
+		result, err := client.RunWorkspacePluginAction("installationid", "com.mattermost.nps", &PluginActionRequest{Action: PluginActionDisable})
+		require.NoError(t, err)
+		assert.Equal(t, PluginActionDisable, result.Action)
+		assert.Equal(t, "Plugin com.mattermost.nps disabled", result.Output)
+		require.NotNil(t, result.Plugin)
+		assert.False(t, result.Plugin.Enabled)
+	})
+
+	t.Run("enable missing plugin", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(pluginListSubcommand, testPluginList)
+
+		result, err := client.RunWorkspacePluginAction("installationid", "github", &PluginActionRequest{Action: PluginActionEnable})
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, result)
+	})
+
+	t.Run("install plugin", func(t *testing.T) {
+		expectWorkspace()
+		gomock.InOrder(
+			expectMmctl([]string{"plugin", "marketplace", "install", "--local", "github", "2.0.0"}, ""),
+			expectMmctl(pluginListSubcommand, `{"active":[],"inactive":[{"id":"github","version":"2.0.0"}]}`),
+		)
+
+		result, err := client.RunWorkspacePluginAction("installationid", "github", &PluginActionRequest{Action: PluginActionInstall, Version: "2.0.0"})
+		require.NoError(t, err)
+		require.NotNil(t, result.Plugin)
+		assert.Equal(t, "2.0.0", result.Plugin.Version)
+	})
+
+	t.Run("invalid plugin ID", func(t *testing.T) {
+		result, err := client.RunWorkspacePluginAction("installationid", "--help", &PluginActionRequest{Action: PluginActionEnable})
+		assert.EqualError(t, err, "failed with status code 400: invalid plugin ID \"--help\"")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		result, err := client.RunWorkspacePluginAction("installationid", "github", &PluginActionRequest{Action: "remove"})
+		assert.EqualError(t, err, "failed with status code 400: invalid action \"remove\", must be one of [enable disable install]")
+		assert.Nil(t, result)
+	})
+
+	t.Run("error running mmctl", func(t *testing.T) {
+		expectWorkspace()
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		plugins, err := client.ListWorkspacePlugins("installationid")
+		assert.EqualError(t, err, "failed with status code 500: some error")
+		assert.Nil(t, plugins)
+	})
+}
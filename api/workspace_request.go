@@ -0,0 +1,275 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+	mmv1alpha1 "github.com/mattermost/mattermost-operator/apis/mattermost/v1alpha1"
+)
+
+// AllowedWorkspaceSizes are the sizes a workspace may be changed to by support.
+var AllowedWorkspaceSizes = []string{
+	mmv1alpha1.CloudSize10String,
+	mmv1alpha1.CloudSize100String,
+	mmv1alpha1.Size100String,
+	mmv1alpha1.Size1000String,
+	mmv1alpha1.Size5000String,
+	mmv1alpha1.Size10000String,
+	mmv1alpha1.Size25000String,
+}
+
+// versionPattern matches a valid docker image tag. It only checks the syntax
+// of versions; whether the tag exists is checked against the image registry.
+var versionPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// imagePattern matches a docker image repository, optionally prefixed by a registry host.
+var imagePattern = regexp.MustCompile(`^([a-z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+([._-][a-z0-9]+)*(/[a-z0-9]+([._-][a-z0-9]+)*)*$`)
+
+// ListWorkspacesRequest specifies the parameters available for listing workspaces.
+type ListWorkspacesRequest struct {
+	cloud.GetInstallationsRequest
+	// State filters the workspaces by state. The provisioner cannot filter by
+	// state, so the page is filled by scanning its installations from the
+	// start, up to a limit of 5000 installations.
+	State string
+}
+
+// Validate validates the values of a workspace list request.
+func (request *ListWorkspacesRequest) Validate() error {
+	if request.State != "" && !contains(cloud.AllInstallationStates, request.State) {
+		return errors.Errorf("invalid state %q, must be one of %v", request.State, cloud.AllInstallationStates)
+	}
+	if request.State != "" && request.PerPage > 0 && (request.Page+1)*request.PerPage > maxStateScanInstallations {
+		return errors.Errorf("page %d of %d workspaces is beyond the %d workspaces scanned when filtering by state", request.Page, request.PerPage, maxStateScanInstallations)
+	}
+
+	return nil
+}
+
+// UpdateWorkspaceRequest specifies the parameters available for updating a workspace.
+type UpdateWorkspaceRequest struct {
+	Version *string `json:"version,omitempty"`
+	Image   *string `json:"image,omitempty"`
+	Size    *string `json:"size,omitempty"`
+}
+
+// Validate validates the values of a workspace update request.
+func (request *UpdateWorkspaceRequest) Validate() error {
+	if request.Version == nil && request.Image == nil && request.Size == nil {
+		return errors.New("must provide at least one of version, image or size")
+	}
+	if request.Version != nil && !versionPattern.MatchString(*request.Version) {
+		return errors.Errorf("invalid version %q", *request.Version)
+	}
+	if request.Image != nil && !imagePattern.MatchString(*request.Image) {
+		return errors.Errorf("invalid image %q", *request.Image)
+	}
+	if request.Size != nil && !isAllowedWorkspaceSize(*request.Size) {
+		return errors.Errorf("invalid size %q, must be one of %v", *request.Size, AllowedWorkspaceSizes)
+	}
+
+	return nil
+}
+
+func (request *UpdateWorkspaceRequest) toPatchInstallationRequest() *cloud.PatchInstallationRequest {
+	return &cloud.PatchInstallationRequest{
+		Version: request.Version,
+		Image:   request.Image,
+		Size:    request.Size,
+	}
+}
+
+// DeleteWorkspaceRequest specifies the parameters for deleting a workspace.
+type DeleteWorkspaceRequest struct {
+	// ConfirmDNS must echo the DNS of the workspace being deleted, guarding
+	// against deleting the wrong workspace by mistake.
+	ConfirmDNS string `json:"confirm_dns"`
+}
+
+// Validate validates the values of a workspace deletion request.
+func (request *DeleteWorkspaceRequest) Validate() error {
+	if strings.TrimSpace(request.ConfirmDNS) == "" {
+		return errors.New("must confirm the deletion with the workspace DNS")
+	}
+
+	return nil
+}
+
+const (
+	// UserActionResetPassword sends the user a password reset email.
+	UserActionResetPassword = "reset_password"
+	// UserActionResetMFA turns off multi-factor authentication for the user.
+	UserActionResetMFA = "reset_mfa"
+	// UserActionVerifyEmail marks the email of the user as verified.
+	UserActionVerifyEmail = "verify_email"
+	// UserActionActivate activates a deactivated user.
+	UserActionActivate = "activate"
+	// UserActionDeactivate deactivates the user.
+	UserActionDeactivate = "deactivate"
+	// UserActionPromoteSystemAdmin makes the user a system admin.
+	UserActionPromoteSystemAdmin = "promote_system_admin"
+	// UserActionRevokeSessions would log the user out of every device. It is
+	// not supported and always rejected: mmctl has no command revoking
+	// sessions, and Pillar holds no credentials for the API of workspaces.
+	UserActionRevokeSessions = "revoke_sessions"
+)
+
+// UserActions are the account recovery actions that may be run on workspace users.
+var UserActions = []string{
+	UserActionResetPassword,
+	UserActionResetMFA,
+	UserActionVerifyEmail,
+	UserActionActivate,
+	UserActionDeactivate,
+	UserActionPromoteSystemAdmin,
+}
+
+// UserActionRequest specifies an account recovery action to run on a workspace user.
+type UserActionRequest struct {
+	Action string `json:"action"`
+}
+
+// Validate validates the values of a user action request.
+func (request *UserActionRequest) Validate() error {
+	if !contains(UserActions, request.Action) {
+		return errors.Errorf("invalid action %q, must be one of %v", request.Action, UserActions)
+	}
+
+	return nil
+}
+
+const (
+	// PluginActionEnable enables an installed plugin.
+	PluginActionEnable = "enable"
+	// PluginActionDisable disables an installed plugin.
+	PluginActionDisable = "disable"
+	// PluginActionInstall installs a plugin from the marketplace.
+	PluginActionInstall = "install"
+)
+
+// PluginActions are the actions that may be run on the plugins of workspaces.
+var PluginActions = []string{
+	PluginActionEnable,
+	PluginActionDisable,
+	PluginActionInstall,
+}
+
+// pluginIDPattern matches the IDs Mattermost allows for plugins.
+var pluginIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,189}$`)
+
+// PluginActionRequest specifies an action to run on a workspace plugin.
+type PluginActionRequest struct {
+	Action string `json:"action"`
+	// Version is the marketplace version to install, defaulting to the
+	// latest one. It is only valid when installing.
+	Version string `json:"version,omitempty"`
+}
+
+// Validate validates the values of a plugin action request.
+func (request *PluginActionRequest) Validate() error {
+	if !contains(PluginActions, request.Action) {
+		return errors.Errorf("invalid action %q, must be one of %v", request.Action, PluginActions)
+	}
+	if request.Version == "" {
+		return nil
+	}
+	if request.Action != PluginActionInstall {
+		return errors.Errorf("version is only valid for the %s action", PluginActionInstall)
+	}
+	if !versionPattern.MatchString(request.Version) {
+		return errors.Errorf("invalid version %q", request.Version)
+	}
+
+	return nil
+}
+
+// mmctlNamePattern matches the IDs and names of teams and channels, which
+// must not be mistaken for mmctl flags.
+var mmctlNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// userRefPattern matches the IDs, usernames and emails users are looked up
+// by, which must not be mistaken for mmctl flags.
+var userRefPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]{0,127}$`)
+
+// channelNamePattern matches the names Mattermost allows for channels.
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// maxChannelDisplayNameLength is the longest display name Mattermost allows for channels.
+const maxChannelDisplayNameLength = 64
+
+// MoveChannelRequest specifies the team a workspace channel is moved to.
+type MoveChannelRequest struct {
+	// Team is the ID or name of the team to move the channel to.
+	Team string `json:"team"`
+}
+
+// Validate validates the values of a channel move request.
+func (request *MoveChannelRequest) Validate() error {
+	if !mmctlNamePattern.MatchString(request.Team) {
+		return errors.Errorf("invalid team %q", request.Team)
+	}
+
+	return nil
+}
+
+// RenameChannelRequest specifies the new name or display name of a workspace channel.
+type RenameChannelRequest struct {
+	Name        *string `json:"name,omitempty"`
+	DisplayName *string `json:"display_name,omitempty"`
+}
+
+// Validate validates the values of a channel rename request.
+func (request *RenameChannelRequest) Validate() error {
+	if request.Name == nil && request.DisplayName == nil {
+		return errors.New("must provide at least one of name or display_name")
+	}
+	if request.Name != nil && !channelNamePattern.MatchString(*request.Name) {
+		return errors.Errorf("invalid name %q, must be up to 64 lowercase letters, numbers, dashes or underscores", *request.Name)
+	}
+	if request.DisplayName != nil {
+		displayName := strings.TrimSpace(*request.DisplayName)
+		if displayName == "" || len(displayName) > maxChannelDisplayNameLength {
+			return errors.Errorf("display_name must be between 1 and %d characters", maxChannelDisplayNameLength)
+		}
+	}
+
+	return nil
+}
+
+func isAllowedWorkspaceSize(size string) bool {
+	for _, allowed := range AllowedWorkspaceSizes {
+		if size == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configKeyPattern matches a dot separated config setting path such as ServiceSettings.SiteURL.
+var configKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]+(\.[A-Za-z0-9]+)+$`)
+
+// PatchWorkspaceConfigRequest specifies a single workspace config setting to change.
+//
+// The value may be a string, number, boolean or a list of those.
+type PatchWorkspaceConfigRequest struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// Validate validates the values of a workspace config patch request.
+func (request *PatchWorkspaceConfigRequest) Validate() error {
+	if !configKeyPattern.MatchString(request.Key) {
+		return errors.Errorf("invalid config key %q", request.Key)
+	}
+
+	_, err := configValueArgs(request.Value)
+	if err != nil {
+		return errors.Wrap(err, "invalid config value")
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/pillar/utils"
+)
+
+func TestUpdateWorkspaceRequestValidate(t *testing.T) {
+	testCases := []struct {
+		description string
+		request     *UpdateWorkspaceRequest
+		valid       bool
+	}{
+		{"empty", &UpdateWorkspaceRequest{}, false},
+		{"version", &UpdateWorkspaceRequest{Version: utils.NewString("5.31.0")}, true},
+		{"blank version", &UpdateWorkspaceRequest{Version: utils.NewString("")}, false},
+		{"version with spaces", &UpdateWorkspaceRequest{Version: utils.NewString("5.31 .0")}, false},
+		{"image", &UpdateWorkspaceRequest{Image: utils.NewString("mattermost/mattermost-enterprise-edition")}, true},
+		{"image with registry", &UpdateWorkspaceRequest{Image: utils.NewString("registry.example.com:5000/mattermost/mm-ee")}, true},
+		{"invalid image", &UpdateWorkspaceRequest{Image: utils.NewString("Mattermost/EE")}, false},
+		{"size", &UpdateWorkspaceRequest{Size: utils.NewString("cloud10users")}, true},
+		{"dev size", &UpdateWorkspaceRequest{Size: utils.NewString("miniSingleton")}, false},
+		{"unknown size", &UpdateWorkspaceRequest{Size: utils.NewString("2users")}, false},
+		{"everything", &UpdateWorkspaceRequest{
+			Version: utils.NewString("5.31.0"),
+			Image:   utils.NewString("mattermost/mattermost-enterprise-edition"),
+			Size:    utils.NewString("1000users"),
+		}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := tc.request.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestUserActionRequestValidate(t *testing.T) {
+	for _, action := range UserActions {
+		assert.NoError(t, (&UserActionRequest{Action: action}).Validate(), action)
+	}
+
+	assert.Error(t, (&UserActionRequest{}).Validate())
+	assert.Error(t, (&UserActionRequest{Action: "delete"}).Validate())
+}
+
+func TestMoveChannelRequestValidate(t *testing.T) {
+	assert.NoError(t, (&MoveChannelRequest{Team: "engineering"}).Validate())
+	assert.NoError(t, (&MoveChannelRequest{Team: "j5ktwzf1xbdodjxfazx4kb1qbo"}).Validate())
+
+	assert.Error(t, (&MoveChannelRequest{}).Validate())
+	assert.Error(t, (&MoveChannelRequest{Team: "--force"}).Validate())
+	assert.Error(t, (&MoveChannelRequest{Team: "team:channel"}).Validate())
+}
+
+func TestRenameChannelRequestValidate(t *testing.T) {
+	name := "incidents-2020"
+	displayName := "Incidents 2020"
+	assert.NoError(t, (&RenameChannelRequest{Name: &name}).Validate())
+	assert.NoError(t, (&RenameChannelRequest{DisplayName: &displayName}).Validate())
+	assert.NoError(t, (&RenameChannelRequest{Name: &name, DisplayName: &displayName}).Validate())
+
+	invalidName := "Incidents 2020"
+	blank := " "
+	long := strings.Repeat("a", 65)
+	assert.Error(t, (&RenameChannelRequest{}).Validate())
+	assert.Error(t, (&RenameChannelRequest{Name: &invalidName}).Validate())
+	assert.Error(t, (&RenameChannelRequest{Name: &long}).Validate())
+	assert.Error(t, (&RenameChannelRequest{DisplayName: &blank}).Validate())
+	assert.Error(t, (&RenameChannelRequest{DisplayName: &long}).Validate())
+}
+
+func TestPluginActionRequestValidate(t *testing.T) {
+	for _, action := range PluginActions {
+		assert.NoError(t, (&PluginActionRequest{Action: action}).Validate(), action)
+	}
+	assert.NoError(t, (&PluginActionRequest{Action: PluginActionInstall, Version: "2.0.0"}).Validate())
+
+	assert.Error(t, (&PluginActionRequest{}).Validate())
+	assert.Error(t, (&PluginActionRequest{Action: "remove"}).Validate())
+	assert.Error(t, (&PluginActionRequest{Action: PluginActionEnable, Version: "2.0.0"}).Validate())
+	assert.Error(t, (&PluginActionRequest{Action: PluginActionInstall, Version: "--force"}).Validate())
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+const (
+	// WorkspaceSortDNS sorts searched workspaces by DNS.
+	WorkspaceSortDNS = "dns"
+	// WorkspaceSortVersion sorts searched workspaces by version.
+	WorkspaceSortVersion = "version"
+	// WorkspaceSortSize sorts searched workspaces by size.
+	WorkspaceSortSize = "size"
+	// WorkspaceSortCreateAt sorts searched workspaces by creation time.
+	WorkspaceSortCreateAt = "create_at"
+)
+
+// WorkspaceSortFields are the fields searched workspaces may be sorted by.
+var WorkspaceSortFields = []string{WorkspaceSortDNS, WorkspaceSortVersion, WorkspaceSortSize, WorkspaceSortCreateAt}
+
+// WorkspaceSearch describes the parameters used to search the workspace index.
+type WorkspaceSearch struct {
+	// Query matches workspaces by ID, or by DNS containing every word of it.
+	Query          string
+	State          string
+	Version        string
+	Size           string
+	Edition        string
+	Database       string
+	Filestore      string
+	IncludeDeleted bool
+	Sort           string
+	Descending     bool
+	Page           int
+	PerPage        int
+}
+
+// Validate validates the values of a workspace search.
+func (s *WorkspaceSearch) Validate() error {
+	if s.Sort != "" && !contains(WorkspaceSortFields, s.Sort) {
+		return errors.Errorf("sort must be one of %v", WorkspaceSortFields)
+	}
+	if s.State != "" && !contains(cloud.AllInstallationStates, s.State) {
+		return errors.Errorf("state must be one of %v", cloud.AllInstallationStates)
+	}
+	if s.Page < 0 {
+		return errors.New("page must not be negative")
+	}
+	if s.PerPage < 1 || s.PerPage > 1000 {
+		return errors.New("per_page must be between 1 and 1000")
+	}
+
+	return nil
+}
+
+// WorkspaceIndex is a local copy of the workspaces of the provisioner that
+// can be searched beyond what the provisioner supports.
+type WorkspaceIndex interface {
+	// UpsertWorkspaces adds or updates the given workspaces, marking them as
+	// synced at the given time in milliseconds.
+	UpsertWorkspaces(workspaces []*Workspace, syncedAt int64) error
+	// DeleteWorkspacesSyncedBefore removes the workspaces that were not seen
+	// by a sync since the given time in milliseconds.
+	DeleteWorkspacesSyncedBefore(syncedAt int64) error
+	SearchWorkspaces(search *WorkspaceSearch) ([]*Workspace, error)
+}
+
+// handleSearchWorkspaces responds to GET /api/v1/workspaces/search, searching the local workspace index.
+//
+// The index is synced from the provisioner periodically, so recent changes may not be reflected yet.
+func handleSearchWorkspaces(c *Context, w http.ResponseWriter, r *http.Request) {
+	if c.WorkspaceIndex == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		c.writeAndLogError(w, errors.New("workspace index is not configured"))
+		return
+	}
+
+	search, err := parseWorkspaceSearch(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	workspaces, err := c.WorkspaceIndex.SearchWorkspaces(search)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	b, err := json.Marshal(workspaces)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func parseWorkspaceSearch(r *http.Request) (*WorkspaceSearch, error) {
+	query := r.URL.Query()
+
+	page, perPage, err := parsePaging(query)
+	if err != nil {
+		return nil, err
+	}
+	includeDeleted, err := parseBool(query, "include_deleted", false)
+	if err != nil {
+		return nil, err
+	}
+
+	search := &WorkspaceSearch{
+		Query:          query.Get("q"),
+		State:          query.Get("state"),
+		Version:        query.Get("version"),
+		Size:           query.Get("size"),
+		Edition:        query.Get("edition"),
+		Database:       query.Get("database"),
+		Filestore:      query.Get("filestore"),
+		IncludeDeleted: includeDeleted,
+		Sort:           query.Get("sort"),
+		Page:           page,
+		PerPage:        perPage,
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		search.Descending = true
+	default:
+		return nil, errors.New("order must be asc or desc")
+	}
+
+	err = search.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+// memoryWorkspaceIndex is an in-memory WorkspaceIndex used to observe syncs and searches.
+type memoryWorkspaceIndex struct {
+	mu         sync.Mutex
+	workspaces map[string]*Workspace
+	syncedAt   map[string]int64
+	lastSearch *WorkspaceSearch
+}
+
+func newMemoryWorkspaceIndex() *memoryWorkspaceIndex {
+	return &memoryWorkspaceIndex{
+		workspaces: make(map[string]*Workspace),
+		syncedAt:   make(map[string]int64),
+	}
+}
+
+func (i *memoryWorkspaceIndex) UpsertWorkspaces(workspaces []*Workspace, syncedAt int64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, workspace := range workspaces {
+		i.workspaces[workspace.ID] = workspace
+		i.syncedAt[workspace.ID] = syncedAt
+	}
+
+	return nil
+}
+
+func (i *memoryWorkspaceIndex) DeleteWorkspacesSyncedBefore(syncedAt int64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for id := range i.workspaces {
+		if i.syncedAt[id] < syncedAt {
+			delete(i.workspaces, id)
+			delete(i.syncedAt, id)
+		}
+	}
+
+	return nil
+}
+
+func (i *memoryWorkspaceIndex) SearchWorkspaces(search *WorkspaceSearch) ([]*Workspace, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.lastSearch = search
+
+	workspaces := []*Workspace{}
+	for _, workspace := range i.workspaces {
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Slice(workspaces, func(a, b int) bool { return workspaces[a].ID < workspaces[b].ID })
+
+	return workspaces, nil
+}
+
+func (i *memoryWorkspaceIndex) ids() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ids := []string{}
+	for id := range i.workspaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func TestSearchWorkspaces(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	index := newMemoryWorkspaceIndex()
+	index.UpsertWorkspaces([]*Workspace{{ID: "workspace1", DNS: "acme.cloud.mattermost.com"}}, 1)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:         logger,
+		WorkspaceIndex: index,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+
+	t.Run("success", func(t *testing.T) {
+		search := &WorkspaceSearch{
+			Query:          "acme",
+			State:          cloud.InstallationStateHibernating,
+			Version:        "5.31.0",
+			Size:           "cloud10users",
+			Edition:        WorkspaceEditionProfessional,
+			Database:       "aws-rds",
+			Filestore:      "aws-s3",
+			IncludeDeleted: true,
+			Sort:           WorkspaceSortCreateAt,
+			Descending:     true,
+			Page:           1,
+			PerPage:        10,
+		}
+		workspaces, err := client.SearchWorkspaces(search)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		assert.Equal(t, "workspace1", workspaces[0].ID)
+		assert.Equal(t, search, index.lastSearch)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		_, err := client.SearchWorkspaces(&WorkspaceSearch{})
+		require.NoError(t, err)
+		assert.Equal(t, &WorkspaceSearch{PerPage: 100}, index.lastSearch)
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		workspaces, err := client.SearchWorkspaces(&WorkspaceSearch{Sort: "owner"})
+		assert.EqualError(t, err, "failed with status code 400: sort must be one of [dns version size create_at]")
+		assert.Nil(t, workspaces)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		workspaces, err := client.SearchWorkspaces(&WorkspaceSearch{State: "sleeping"})
+		assert.EqualError(t, err, "failed with status code 400: state must be one of [stable creation-requested creation-pre-provisioning creation-in-progress creation-configuring-dns creation-failed creation-no-compatible-clusters creation-final-tasks hibernation-requested hibernation-in-progress hibernating update-requested update-in-progress update-failed deletion-requested deletion-in-progress deletion-final-cleanup deletion-failed deleted]")
+		assert.Nil(t, workspaces)
+	})
+
+	t.Run("invalid paging", func(t *testing.T) {
+		workspaces, err := client.SearchWorkspaces(&WorkspaceSearch{PerPage: 5000})
+		assert.EqualError(t, err, "failed with status code 400: per_page must be between 1 and 1000")
+		assert.Nil(t, workspaces)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		router := mux.NewRouter()
+		Register(router, &Context{Logger: logger})
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+
+		workspaces, err := NewClient(ts.URL).SearchWorkspaces(&WorkspaceSearch{})
+		assert.EqualError(t, err, "failed with status code 501: workspace index is not configured")
+		assert.Nil(t, workspaces)
+	})
+}
+
+func TestWorkspaceSyncer(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	index := newMemoryWorkspaceIndex()
+	index.UpsertWorkspaces([]*Workspace{{ID: "removed"}}, 1)
+
+	syncer := NewWorkspaceSyncer(mockCloudClient, index, 0, logger)
+
+	t.Run("pages through installations", func(t *testing.T) {
+		firstPage := make([]*cloud.InstallationDTO, workspaceSyncPerPage)
+		for i := range firstPage {
+			firstPage[i] = &cloud.InstallationDTO{Installation: &cloud.Installation{ID: fmt.Sprintf("installation%03d", i)}}
+		}
+		secondPage := []*cloud.InstallationDTO{{Installation: &cloud.Installation{ID: "last"}}}
+
+		mockCloudClient.EXPECT().
+			GetInstallations(gomock.Any(), gomock.Eq(&cloud.GetInstallationsRequest{Page: 0, PerPage: workspaceSyncPerPage, IncludeDeleted: true})).
+			Times(1).
+			Return(firstPage, nil)
+		mockCloudClient.EXPECT().
+			GetInstallations(gomock.Any(), gomock.Eq(&cloud.GetInstallationsRequest{Page: 1, PerPage: workspaceSyncPerPage, IncludeDeleted: true})).
+			Times(1).
+			Return(secondPage, nil)
+
+		err := syncer.Sync(context.Background())
+		require.NoError(t, err)
+
+		ids := index.ids()
+		assert.Len(t, ids, workspaceSyncPerPage+1)
+		assert.Contains(t, ids, "last")
+		assert.NotContains(t, ids, "removed")
+	})
+
+	t.Run("failed syncs keep the index", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallations(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		err := syncer.Sync(context.Background())
+		assert.Error(t, err)
+		assert.Len(t, index.ids(), workspaceSyncPerPage+1)
+	})
+
+	t.Run("bypasses the cache", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallations(gomock.Any(), gomock.Any()).Times(2).Return([]*cloud.InstallationDTO{}, nil)
+
+		cachedSyncer := NewWorkspaceSyncer(NewCachingCloudClient(mockCloudClient, NewMemoryCache(), DefaultCacheTTLs, logger), index, 0, logger)
+		for i := 0; i < 2; i++ {
+			err := cachedSyncer.Sync(context.Background())
+			require.NoError(t, err)
+		}
+	})
+}
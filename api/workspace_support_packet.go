@@ -0,0 +1,226 @@
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// supportPacketLogLines is the number of recent server log lines included in support packets.
+const supportPacketLogLines = 1000
+
+// supportPacketStepTimeout bounds gathering each part of a support packet. The
+// cluster installation lookup and the five mmctl commands run one after the
+// other, so together they stay within the write timeout of the server.
+const supportPacketStepTimeout = 25 * time.Second
+
+// serverVersionSubcommand is the mmctl subcommand printing the version of the server of a cluster installation.
+var serverVersionSubcommand = []string{"system", "version", "--local", "--json"}
+
+// serverLogsSubcommand is the mmctl subcommand printing the recent logs of the server of a cluster installation.
+var serverLogsSubcommand = []string{"logs", "--local", "--number", strconv.Itoa(supportPacketLogLines)}
+
+// supportPacket writes the files of a support packet to a zip file as they
+// are gathered, collecting the reasons any part of it could not be gathered.
+type supportPacket struct {
+	zip    *zip.Writer
+	w      io.Writer
+	errors []string
+	// stepTimeout bounds each step gathering a part of the packet.
+	stepTimeout time.Duration
+	// err is the first failure to write the zip file, after which nothing
+	// more is written.
+	err error
+}
+
+func newSupportPacket(w io.Writer) *supportPacket {
+	return &supportPacket{zip: zip.NewWriter(w), w: w, stepTimeout: supportPacketStepTimeout}
+}
+
+func (p *supportPacket) add(name string, data []byte) {
+	if p.err != nil {
+		return
+	}
+
+	fileWriter, err := p.zip.Create(name)
+	if err != nil {
+		p.err = errors.Wrapf(err, "failed to create %s", name)
+		return
+	}
+
+	_, err = fileWriter.Write(data)
+	if err != nil {
+		p.err = errors.Wrapf(err, "failed to write %s", name)
+		return
+	}
+
+	// Each file is sent on as soon as it is written, rather than buffered
+	// until the packet is complete.
+	err = p.zip.Flush()
+	if err != nil {
+		p.err = errors.Wrapf(err, "failed to write %s", name)
+		return
+	}
+	if flusher, ok := p.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (p *supportPacket) addJSON(name string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		p.addError(name, err)
+		return
+	}
+
+	p.add(name, data)
+}
+
+func (p *supportPacket) addError(name string, err error) {
+	p.errors = append(p.errors, fmt.Sprintf("%s: %s", name, err))
+}
+
+// gather runs a step gathering the named part of the packet, bounded by the
+// step timeout, and records its failure if it returns an error.
+func (p *supportPacket) gather(ctx context.Context, name string, step func(ctx context.Context) error) {
+	stepCtx, cancel := context.WithTimeout(ctx, p.stepTimeout)
+	defer cancel()
+
+	err := step(stepCtx)
+	if err != nil {
+		if stepCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = errors.Errorf("timed out after %s", p.stepTimeout)
+		}
+		p.addError(name, err)
+	}
+}
+
+// close writes errors.txt, if any part could not be gathered, and finishes
+// the zip file, returning the first failure to write it.
+func (p *supportPacket) close() error {
+	if len(p.errors) > 0 {
+		p.add("errors.txt", []byte(strings.Join(p.errors, "\n")+"\n"))
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	return p.zip.Close()
+}
+
+// handleGetWorkspaceSupportPacket responds to POST /api/v1/workspaces/{id}/support-packet, gathering the redacted
+// config, plugins, server version and status and provisioner installation record of the workspace into a zip file.
+// The recent server logs are included only for callers allowed to read them, as they are not redacted.
+//
+// Each part is written to the response as soon as it is gathered. Parts that cannot be gathered, or that take longer
+// than supportPacketStepTimeout, are left out and explained in errors.txt, so that a packet is returned even for a
+// workspace that is down.
+func handleGetWorkspaceSupportPacket(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	installation, err := c.CloudClient.GetInstallation(r.Context(), workspaceID, &cloud.GetInstallationRequest{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if installation == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", workspaceID+"-support-packet.zip"))
+	w.WriteHeader(http.StatusOK)
+
+	packet := newSupportPacket(w)
+	packet.addJSON("installation.json", convertInstallationToWorkspace(installation, c.redactor()))
+
+	var clusterInstallation *cloud.ClusterInstallation
+	packet.gather(r.Context(), "cluster installation", func(ctx context.Context) error {
+		clusterInstallation, err = getClusterInstallationForWorkspace(ctx, c.CloudClient, workspaceID)
+		return err
+	})
+	if clusterInstallation != nil {
+		collectServerSupportPacket(r.Context(), c, clusterInstallation.ID, packet)
+	}
+
+	if len(packet.errors) > 0 {
+		c.Logger.WithField("errors", packet.errors).Warn("Support packet is incomplete")
+	}
+
+	// The status was sent already, so failures to write the zip can only be logged.
+	err = packet.close()
+	if err != nil {
+		c.Logger.WithError(err).Error("Failed to write support packet")
+	}
+}
+
+// collectServerSupportPacket adds everything gathered from the server of the
+// cluster installation with mmctl to the support packet.
+func collectServerSupportPacket(ctx context.Context, c *Context, clusterInstallationID string, packet *supportPacket) {
+	packet.gather(ctx, "config.json", func(ctx context.Context) error {
+		config, err := getConfigForClusterInstallation(ctx, c.CloudClient, clusterInstallationID)
+		if err != nil {
+			return err
+		}
+		packet.addJSON("config.json", c.redactor().RedactConfig(config))
+		return nil
+	})
+
+	packet.gather(ctx, "plugins.json", func(ctx context.Context) error {
+		plugins, err := getPluginsForClusterInstallation(ctx, c.CloudClient, clusterInstallationID)
+		if err != nil {
+			return err
+		}
+		packet.addJSON("plugins.json", plugins)
+		return nil
+	})
+
+	for _, command := range []struct {
+		name string
+		args []string
+	}{
+		{"version.json", serverVersionSubcommand},
+		{"system_status.json", serverStatusSubcommand},
+	} {
+		command := command
+		packet.gather(ctx, command.name, func(ctx context.Context) error {
+			values, err := execMmctlJSON(ctx, c.CloudClient, clusterInstallationID, command.args)
+			if err != nil {
+				return err
+			}
+			if len(values) == 0 {
+				return errors.New("mmctl printed nothing")
+			}
+			packet.addJSON(command.name, values[0])
+			return nil
+		})
+	}
+
+	if !c.can(PermissionWorkspaceLogRead) {
+		packet.addError("mattermost.log", errors.Errorf("left out, reading server logs requires the %s permission", PermissionWorkspaceLogRead))
+		return
+	}
+	packet.gather(ctx, "mattermost.log", func(ctx context.Context) error {
+		logs, err := c.CloudClient.ExecClusterInstallationCLI(ctx, clusterInstallationID, "mmctl", serverLogsSubcommand)
+		if err != nil {
+			return err
+		}
+		packet.add("mattermost.log", logs)
+		return nil
+	})
+}
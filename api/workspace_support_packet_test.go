@@ -0,0 +1,163 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+// readSupportPacket returns the contents of every file of a support packet by name.
+func readSupportPacket(t *testing.T, packet []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(packet), int64(len(packet)))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		f, err := file.Open()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(f)
+		require.NoError(t, err)
+		f.Close()
+		files[file.Name] = string(data)
+	}
+
+	return files
+}
+
+func TestSupportPacketStreaming(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	packet := newSupportPacket(recorder)
+
+	packet.add("version.json", []byte(`{"version":"5.30.0"}`))
+	assert.True(t, recorder.Flushed)
+	assert.NotZero(t, recorder.Body.Len(), "files are written as they are added")
+
+	packet.addError("mattermost.log", errors.New("some error"))
+	require.NoError(t, packet.close())
+
+	files := readSupportPacket(t, recorder.Body.Bytes())
+	assert.Equal(t, `{"version":"5.30.0"}`, files["version.json"])
+	assert.Equal(t, "mattermost.log: some error\n", files["errors.txt"])
+}
+
+func TestSupportPacketStepTimeout(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	packet := newSupportPacket(recorder)
+	packet.stepTimeout = 10 * time.Millisecond
+
+	packet.gather(context.Background(), "mattermost.log", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	packet.gather(context.Background(), "version.json", func(ctx context.Context) error {
+		packet.add("version.json", []byte(`{"version":"5.30.0"}`))
+		return nil
+	})
+	require.NoError(t, packet.close())
+
+	files := readSupportPacket(t, recorder.Body.Bytes())
+	assert.Equal(t, `{"version":"5.30.0"}`, files["version.json"])
+	assert.Equal(t, "mattermost.log: timed out after 10ms\n", files["errors.txt"])
+}
+
+func TestWorkspaceSupportPacket(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("customer reported outage", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{
+		ID:    "installationid",
+		DNS:   "acme.cloud.mattermost.com",
+		State: cloud.InstallationStateStable,
+		MattermostEnv: cloud.EnvVarMap{
+			"MM_SQLSETTINGS_DATASOURCE": {Value: "postgres://user:secret@db"},
+		},
+	}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+	expectMmctl := func(args []string, output string, err error) {
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(args)).
+			Times(1).
+			Return([]byte(output), err)
+	}
+
+	t.Run("complete packet", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		expectMmctl(configShowSubcommand, `{"SqlSettings":{"DataSource":"postgres://user:secret@db"},"ServiceSettings":{"SiteURL":"https://acme.cloud.mattermost.com"}}`, nil)
+		expectMmctl(pluginListSubcommand, testPluginList, nil)
+		expectMmctl(serverVersionSubcommand, `{"version":"5.30.1"}`, nil)
+		expectMmctl(serverStatusSubcommand, `{"status":"OK","database_status":"OK","filestore_status":"OK"}`, nil)
+		expectMmctl(serverLogsSubcommand, "{\"level\":\"info\",\"msg\":\"Server is listening\"}\n", nil)
+
+		buf := &bytes.Buffer{}
+		err := client.DownloadWorkspaceSupportPacket("installationid", buf)
+		require.NoError(t, err)
+
+		files := readSupportPacket(t, buf.Bytes())
+		assert.Len(t, files, 6)
+		assert.NotContains(t, files, "errors.txt")
+		assert.Contains(t, files["installation.json"], "acme.cloud.mattermost.com")
+		assert.NotContains(t, files["installation.json"], "secret")
+		assert.Contains(t, files["config.json"], "https://acme.cloud.mattermost.com")
+		assert.NotContains(t, files["config.json"], "secret")
+		assert.Contains(t, files["plugins.json"], "com.mattermost.nps")
+		assert.Contains(t, files["version.json"], "5.30.1")
+		assert.Contains(t, files["system_status.json"], "database_status")
+		assert.Contains(t, files["mattermost.log"], "Server is listening")
+	})
+
+	t.Run("server unavailable", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(5).
+			Return(nil, errors.New("pod not ready"))
+
+		buf := &bytes.Buffer{}
+		err := client.DownloadWorkspaceSupportPacket("installationid", buf)
+		require.NoError(t, err)
+
+		files := readSupportPacket(t, buf.Bytes())
+		assert.Len(t, files, 2)
+		assert.Contains(t, files, "installation.json")
+		assert.Contains(t, files["errors.txt"], "mattermost.log: pod not ready")
+	})
+
+	t.Run("workspace not found", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("missing"), gomock.Any()).Times(1).Return(nil, nil)
+
+		buf := &bytes.Buffer{}
+		err := client.DownloadWorkspaceSupportPacket("missing", buf)
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Zero(t, buf.Len())
+	})
+}
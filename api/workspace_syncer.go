@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+// workspaceSyncPerPage is the number of installations fetched per page while syncing.
+const workspaceSyncPerPage = 100
+
+// WorkspaceSyncer periodically copies every workspace of the provisioner into
+// the workspace index.
+type WorkspaceSyncer struct {
+	client   CloudClient
+	index    WorkspaceIndex
+	interval time.Duration
+	logger   logrus.FieldLogger
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWorkspaceSyncer creates a syncer copying workspaces into the given index at the given interval.
+func NewWorkspaceSyncer(client CloudClient, index WorkspaceIndex, interval time.Duration, logger logrus.FieldLogger) *WorkspaceSyncer {
+	return &WorkspaceSyncer{
+		client:   client,
+		index:    index,
+		interval: interval,
+		logger:   logger.WithField("component", "workspace-syncer"),
+		now:      time.Now,
+	}
+}
+
+// Start syncs the workspace index in the background, immediately and then at
+// every interval, until Stop is called.
+func (s *WorkspaceSyncer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			err := s.Sync(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.WithError(err).Error("Failed to sync workspace index")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops syncing, waiting for a sync in progress to be cancelled.
+func (s *WorkspaceSyncer) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+}
+
+// Sync pages through every installation of the provisioner, copying them into
+// the index. Workspaces no longer known to the provisioner are removed once
+// every page was synced.
+func (s *WorkspaceSyncer) Sync(ctx context.Context) error {
+	// The index must reflect the provisioner rather than the cache in front of it.
+	ctx = withCacheRefresh(ctx)
+
+	start := s.now()
+	syncedAt := start.UnixNano() / int64(time.Millisecond)
+
+	count := 0
+	for page := 0; ; page++ {
+		installations, err := s.client.GetInstallations(ctx, &cloud.GetInstallationsRequest{
+			Page:           page,
+			PerPage:        workspaceSyncPerPage,
+			IncludeDeleted: true,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get installations page %d", page)
+		}
+
+		err = s.index.UpsertWorkspaces(convertInstallationsToWorkspaces(installations, defaultRedactor), syncedAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to update workspace index")
+		}
+		count += len(installations)
+
+		if len(installations) < workspaceSyncPerPage {
+			break
+		}
+	}
+
+	err := s.index.DeleteWorkspacesSyncedBefore(syncedAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove stale workspaces from index")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"workspaces": count,
+		"duration":   s.now().Sub(start).String(),
+	}).Debug("Synced workspace index")
+
+	return nil
+}
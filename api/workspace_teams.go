@@ -0,0 +1,440 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TeamPrivacyOpen is a team anyone on the workspace may join.
+	TeamPrivacyOpen = "open"
+	// TeamPrivacyInviteOnly is a team users must be invited to.
+	TeamPrivacyInviteOnly = "invite_only"
+
+	// ChannelPrivacyPublic is a channel any member of the team may join.
+	ChannelPrivacyPublic = "public"
+	// ChannelPrivacyPrivate is a channel users must be added to.
+	ChannelPrivacyPrivate = "private"
+	// ChannelPrivacyDirect is a direct message between two users.
+	ChannelPrivacyDirect = "direct"
+	// ChannelPrivacyGroup is a group message between several users.
+	ChannelPrivacyGroup = "group"
+)
+
+// Team is a team of a workspace.
+type Team struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	// Privacy is either open or invite_only.
+	Privacy  string `json:"privacy"`
+	Archived bool   `json:"archived"`
+	// MemberCount is the number of users that are members of the team,
+	// including deactivated ones. Counting takes a command per team, so it is
+	// only set when requested.
+	MemberCount *int  `json:"member_count,omitempty"`
+	CreateAt    int64 `json:"create_at"`
+	DeleteAt    int64 `json:"delete_at"`
+}
+
+// Channel is a channel of a workspace team.
+//
+// mmctl offers no way to count the members of a channel, so unlike teams,
+// channels do not report a member count.
+type Channel struct {
+	ID          string `json:"id"`
+	TeamID      string `json:"team_id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	// Privacy is one of public, private, direct or group.
+	Privacy       string `json:"privacy"`
+	Archived      bool   `json:"archived"`
+	Purpose       string `json:"purpose"`
+	TotalMsgCount int64  `json:"total_msg_count"`
+	LastPostAt    int64  `json:"last_post_at"`
+	CreateAt      int64  `json:"create_at"`
+	DeleteAt      int64  `json:"delete_at"`
+}
+
+// mmctlTeam holds the fields of a team printed by mmctl.
+type mmctlTeam struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	CreateAt    int64  `json:"create_at"`
+	DeleteAt    int64  `json:"delete_at"`
+}
+
+// mmctlChannel holds the fields of a channel printed by mmctl.
+type mmctlChannel struct {
+	ID            string `json:"id"`
+	TeamID        string `json:"team_id"`
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+	Type          string `json:"type"`
+	Purpose       string `json:"purpose"`
+	TotalMsgCount int64  `json:"total_msg_count"`
+	LastPostAt    int64  `json:"last_post_at"`
+	CreateAt      int64  `json:"create_at"`
+	DeleteAt      int64  `json:"delete_at"`
+}
+
+// handleListWorkspaceTeams responds to GET /api/v1/workspaces/{id}/teams, listing the teams of the workspace with
+// mmctl, including archived ones. The q parameter filters teams by partial name or display name.
+//
+// The members of each listed team are only counted if the member_counts parameter is true, as this runs a command
+// per team.
+func handleListWorkspaceTeams(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	memberCounts, err := parseBool(r.URL.Query(), "member_counts", false)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	teams, err := getTeamsForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID, teamListSubcommand)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	filtered := []*Team{}
+	for _, team := range teams {
+		if matchesQuery(query, team.Name, team.DisplayName) {
+			filtered = append(filtered, team)
+		}
+	}
+
+	for _, team := range filtered {
+		if !memberCounts {
+			break
+		}
+
+		var memberCount int
+		memberCount, err = getTeamMemberCount(r.Context(), c.CloudClient, clusterInstallation.ID, team.ID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			c.writeAndLogError(w, errors.Wrapf(err, "failed to count the members of team %s", team.Name))
+			return
+		}
+		team.MemberCount = &memberCount
+	}
+
+	b, err := json.Marshal(filtered)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleListWorkspaceTeamChannels responds to GET /api/v1/workspaces/{id}/teams/{team}/channels, listing the
+// channels of a team of the workspace with mmctl, including private and archived ones. The team may be given by ID
+// or name, and the q parameter filters channels by partial name or display name.
+func handleListWorkspaceTeamChannels(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	teamArg := vars["team"]
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "team": teamArg})
+
+	if !mmctlNamePattern.MatchString(teamArg) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid team %q", teamArg))
+		return
+	}
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	team, err := findTeam(r.Context(), c.CloudClient, clusterInstallation.ID, teamArg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if team == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	channels, err := getChannelsForClusterInstallation(r.Context(), c.CloudClient, clusterInstallation.ID, channelListArgs(team))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	filtered := []*Channel{}
+	for _, channel := range channels {
+		if matchesQuery(query, channel.Name, channel.DisplayName) {
+			filtered = append(filtered, channel)
+		}
+	}
+
+	b, err := json.Marshal(filtered)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleGetWorkspaceChannel responds to GET /api/v1/workspaces/{id}/channels/{channel}, fetching a single channel
+// of the workspace, archived or not, by ID, or by ID or name within the team given by the team parameter.
+func handleGetWorkspaceChannel(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	channelArg := vars["channel"]
+	teamArg := r.URL.Query().Get("team")
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "channel": channelArg, "team": teamArg})
+
+	if !mmctlNamePattern.MatchString(channelArg) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid channel %q", channelArg))
+		return
+	}
+	if teamArg != "" && !mmctlNamePattern.MatchString(teamArg) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid team %q", teamArg))
+		return
+	}
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	channel, err := findChannel(r.Context(), c.CloudClient, clusterInstallation.ID, teamArg, channelArg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if channel == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	b, err := json.Marshal(channel)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// matchesQuery returns true if every word of the query is part of any of the
+// given values, regardless of case. An empty query matches everything.
+func matchesQuery(query string, values ...string) bool {
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		found := false
+		for _, value := range values {
+			if strings.Contains(strings.ToLower(value), word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// teamListSubcommand is the mmctl subcommand listing the teams of a cluster installation, including archived ones.
+var teamListSubcommand = []string{"team", "list", "--local", "--json"}
+
+// channelListArgs returns the mmctl arguments listing the channels of the
+// given teams, including private and archived ones.
+func channelListArgs(teams ...*Team) []string {
+	args := []string{"channel", "list", "--local", "--json"}
+	for _, team := range teams {
+		args = append(args, team.ID)
+	}
+
+	return args
+}
+
+// findTeam returns the team of the cluster installation with the given ID or
+// name, archived or not, or nil if there is none.
+//
+// Teams are listed and matched here rather than looked up by mmctl, which
+// fails when a team is missing, so that a missing team can be told apart from
+// a failure to reach the workspace.
+func findTeam(ctx context.Context, client CloudClient, clusterInstallationID, teamArg string) (*Team, error) {
+	teams, err := getTeamsForClusterInstallation(ctx, client, clusterInstallationID, teamListSubcommand)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if team.ID == teamArg || team.Name == teamArg {
+			return team, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findChannel returns the channel of the cluster installation, archived or
+// not, with the given ID or, if a team is given, the channel of that team
+// with the given ID or name. It returns nil if there is no such channel.
+//
+// mmctl only searches for channels by name and skips archived ones, so the
+// channels are listed and matched here instead. Without a team, the channels
+// of every team are listed, as mmctl cannot fetch a channel by ID alone.
+func findChannel(ctx context.Context, client CloudClient, clusterInstallationID, teamArg, channelArg string) (*Channel, error) {
+	var teams []*Team
+	if teamArg != "" {
+		team, err := findTeam(ctx, client, clusterInstallationID, teamArg)
+		if err != nil || team == nil {
+			return nil, err
+		}
+		teams = []*Team{team}
+	} else {
+		var err error
+		teams, err = getTeamsForClusterInstallation(ctx, client, clusterInstallationID, teamListSubcommand)
+		if err != nil {
+			return nil, err
+		}
+		if len(teams) == 0 {
+			return nil, nil
+		}
+	}
+
+	channels, err := getChannelsForClusterInstallation(ctx, client, clusterInstallationID, channelListArgs(teams...))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range channels {
+		// Channel names are only unique within a team.
+		if channel.ID == channelArg || (teamArg != "" && channel.Name == channelArg) {
+			return channel, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getTeamsForClusterInstallation runs an mmctl command printing teams against the cluster installation.
+func getTeamsForClusterInstallation(ctx context.Context, client CloudClient, clusterInstallationID string, args []string) ([]*Team, error) {
+	values, err := execMmctlJSON(ctx, client, clusterInstallationID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := []*Team{}
+	for _, value := range values {
+		team := &mmctlTeam{}
+		err = json.Unmarshal(value, team)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode team")
+		}
+
+		privacy := TeamPrivacyInviteOnly
+		if team.Type == "O" {
+			privacy = TeamPrivacyOpen
+		}
+
+		teams = append(teams, &Team{
+			ID:          team.ID,
+			Name:        team.Name,
+			DisplayName: team.DisplayName,
+			Privacy:     privacy,
+			Archived:    team.DeleteAt != 0,
+			CreateAt:    team.CreateAt,
+			DeleteAt:    team.DeleteAt,
+		})
+	}
+
+	return teams, nil
+}
+
+// getTeamMemberCount counts the users of the team with mmctl.
+func getTeamMemberCount(ctx context.Context, client CloudClient, clusterInstallationID, teamID string) (int, error) {
+	values, err := execMmctlJSON(ctx, client, clusterInstallationID, []string{"user", "list", "--local", "--json", "--all", "--team", teamID})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(values), nil
+}
+
+// getChannelsForClusterInstallation runs an mmctl command printing channels against the cluster installation.
+func getChannelsForClusterInstallation(ctx context.Context, client CloudClient, clusterInstallationID string, args []string) ([]*Channel, error) {
+	values, err := execMmctlJSON(ctx, client, clusterInstallationID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := []*Channel{}
+	for _, value := range values {
+		channel := &mmctlChannel{}
+		err = json.Unmarshal(value, channel)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode channel")
+		}
+
+		channels = append(channels, &Channel{
+			ID:            channel.ID,
+			TeamID:        channel.TeamID,
+			Name:          channel.Name,
+			DisplayName:   channel.DisplayName,
+			Privacy:       channelPrivacy(channel.Type),
+			Archived:      channel.DeleteAt != 0,
+			Purpose:       channel.Purpose,
+			TotalMsgCount: channel.TotalMsgCount,
+			LastPostAt:    channel.LastPostAt,
+			CreateAt:      channel.CreateAt,
+			DeleteAt:      channel.DeleteAt,
+		})
+	}
+
+	return channels, nil
+}
+
+// channelPrivacy maps the type of a Mattermost channel to its privacy.
+func channelPrivacy(channelType string) string {
+	switch channelType {
+	case "P":
+		return ChannelPrivacyPrivate
+	case "D":
+		return ChannelPrivacyDirect
+	case "G":
+		return ChannelPrivacyGroup
+	}
+
+	return ChannelPrivacyPublic
+}
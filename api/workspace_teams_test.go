@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestMatchesQuery(t *testing.T) {
+	assert.True(t, matchesQuery("", "town-square"))
+	assert.True(t, matchesQuery("Town", "town-square", "Town Square"))
+	assert.True(t, matchesQuery("town square", "town-square"))
+	assert.False(t, matchesQuery("town hall", "town-square", "Town Square"))
+}
+
+func TestWorkspaceTeams(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("customer is missing a channel", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+	expectWorkspace := func() {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+	}
+	expectMmctl := func(args []string, output string) *gomock.Call {
+		return mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq(args)).
+			Times(1).
+			Return([]byte(output), nil)
+	}
+
+	teamList := []string{"team", "list", "--local", "--json"}
+	teamListOutput := `[{"id":"team1","name":"engineering","display_name":"Engineering","type":"O"},{"id":"team2","name":"sales","display_name":"Sales","type":"I","delete_at":10}]`
+	channelListOutput := `[{"id":"channel1","team_id":"team1","name":"town-square","type":"O"},{"id":"channel2","team_id":"team1","name":"incidents","type":"P","total_msg_count":5,"delete_at":20}]`
+
+	t.Run("list teams", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+
+		teams, err := client.ListWorkspaceTeams("installationid", "sale", false)
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		assert.Equal(t, &Team{ID: "team2", Name: "sales", DisplayName: "Sales", Privacy: TeamPrivacyInviteOnly, Archived: true, DeleteAt: 10}, teams[0])
+	})
+
+	t.Run("list teams with member counts", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"user", "list", "--local", "--json", "--all", "--team", "team2"}, `[{"id":"user1"},{"id":"user2"}]`)
+
+		teams, err := client.ListWorkspaceTeams("installationid", "sale", true)
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		require.NotNil(t, teams[0].MemberCount)
+		assert.Equal(t, 2, *teams[0].MemberCount)
+	})
+
+	t.Run("list team channels", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1"}, channelListOutput)
+
+		channels, err := client.ListWorkspaceTeamChannels("installationid", "engineering", "")
+		require.NoError(t, err)
+		require.Len(t, channels, 2)
+		assert.Equal(t, ChannelPrivacyPublic, channels[0].Privacy)
+		assert.False(t, channels[0].Archived)
+		assert.Equal(t, ChannelPrivacyPrivate, channels[1].Privacy)
+		assert.True(t, channels[1].Archived)
+	})
+
+	t.Run("team not found", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+
+		channels, err := client.ListWorkspaceTeamChannels("installationid", "missing", "")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, channels)
+	})
+
+	t.Run("invalid team", func(t *testing.T) {
+		channels, err := client.ListWorkspaceTeamChannels("installationid", "--help", "")
+		assert.EqualError(t, err, "failed with status code 400: invalid team \"--help\"")
+		assert.Nil(t, channels)
+	})
+
+	t.Run("get archived channel by name", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1"}, channelListOutput)
+
+		channel, err := client.GetWorkspaceChannel("installationid", "incidents", "engineering")
+		require.NoError(t, err)
+		assert.Equal(t, "channel2", channel.ID)
+		assert.True(t, channel.Archived)
+		assert.Equal(t, int64(5), channel.TotalMsgCount)
+	})
+
+	t.Run("get channel by ID", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1", "team2"}, channelListOutput)
+
+		channel, err := client.GetWorkspaceChannel("installationid", "channel2", "")
+		require.NoError(t, err)
+		assert.Equal(t, "incidents", channel.Name)
+	})
+
+	t.Run("channel name without team", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1", "team2"}, channelListOutput)
+
+		channel, err := client.GetWorkspaceChannel("installationid", "incidents", "")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, channel)
+	})
+
+	t.Run("channel not found", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+		expectMmctl([]string{"channel", "list", "--local", "--json", "team1"}, channelListOutput)
+
+		channel, err := client.GetWorkspaceChannel("installationid", "missing", "engineering")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, channel)
+	})
+
+	t.Run("channel of missing team", func(t *testing.T) {
+		expectWorkspace()
+		expectMmctl(teamList, teamListOutput)
+
+		channel, err := client.GetWorkspaceChannel("installationid", "incidents", "missing")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, channel)
+	})
+
+	t.Run("invalid channel", func(t *testing.T) {
+		channel, err := client.GetWorkspaceChannel("installationid", "-incidents", "")
+		assert.EqualError(t, err, "failed with status code 400: invalid channel \"-incidents\"")
+		assert.Nil(t, channel)
+
+		channel, err = client.GetWorkspaceChannel("installationid", "incidents", "--team")
+		assert.EqualError(t, err, "failed with status code 400: invalid team \"--team\"")
+		assert.Nil(t, channel)
+	})
+
+	t.Run("error running mmctl", func(t *testing.T) {
+		expectWorkspace()
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		teams, err := client.ListWorkspaceTeams("installationid", "", false)
+		assert.EqualError(t, err, "failed with status code 500: some error")
+		assert.Nil(t, teams)
+	})
+}
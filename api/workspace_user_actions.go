@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// UserActionResult describes the outcome of an account recovery action.
+type UserActionResult struct {
+	Action string `json:"action"`
+	// Output is what mmctl printed while running the action.
+	Output string `json:"output"`
+	// User is the user after the action, if it could be fetched again.
+	User *User `json:"user,omitempty"`
+}
+
+// userActionCommands are the mmctl commands run for each action, given the ID of the user.
+var userActionCommands = map[string]func(userID string) []string{
+	UserActionResetPassword: func(userID string) []string {
+		return []string{"user", "reset_password", "--local", userID}
+	},
+	UserActionResetMFA: func(userID string) []string {
+		return []string{"user", "resetmfa", "--local", userID}
+	},
+	UserActionVerifyEmail: func(userID string) []string {
+		return []string{"user", "verify", "--local", userID}
+	},
+	UserActionActivate: func(userID string) []string {
+		return []string{"user", "activate", "--local", userID}
+	},
+	UserActionDeactivate: func(userID string) []string {
+		return []string{"user", "deactivate", "--local", userID}
+	},
+	UserActionPromoteSystemAdmin: func(userID string) []string {
+		return []string{"roles", "system_admin", "--local", userID}
+	},
+}
+
+// handleWorkspaceUserAction responds to POST /api/v1/workspaces/{id}/users/{user}/actions, running an account
+// recovery action on a user of the workspace with mmctl.
+//
+// Promoting a user to system admin additionally requires the workspace:user:promote permission.
+// Revoking sessions is rejected as not implemented.
+func handleWorkspaceUserAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	userArg := vars["user"]
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "user": userArg})
+
+	if !userRefPattern.MatchString(userArg) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid user ID, email or username %q", userArg))
+		return
+	}
+
+	actionRequest := &UserActionRequest{}
+	err := decodeJSON(actionRequest, r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	if actionRequest.Action == UserActionRevokeSessions {
+		w.WriteHeader(http.StatusNotImplemented)
+		c.writeAndLogError(w, errors.New("revoking sessions is not supported, as mmctl cannot revoke sessions and Pillar holds no credentials for the workspace API; deactivating the user revokes their sessions"))
+		return
+	}
+
+	err = actionRequest.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+	c.Logger = c.Logger.WithField("action", actionRequest.Action)
+
+	if actionRequest.Action == UserActionPromoteSystemAdmin && !c.can(PermissionWorkspaceUserPromote) {
+		w.WriteHeader(http.StatusForbidden)
+		c.writeAndLogError(w, errors.Errorf("promoting users requires the %s permission", PermissionWorkspaceUserPromote))
+		return
+	}
+
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return
+	}
+
+	// The user is looked up first so that the action is run against exactly
+	// one user, whether it was given by ID, email or username.
+	userSearch := []string{"user", "search", "--local", "--json", userArg}
+	users, err := execUserCommand(r.Context(), c.CloudClient, clusterInstallation.ID, userSearch)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+	if len(users) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	user := users[0]
+
+	c.Logger.WithField("user_id", user.ID).Info("Running user action")
+
+	args := userActionCommands[actionRequest.Action](user.ID)
+	output, err := c.CloudClient.ExecClusterInstallationCLI(r.Context(), clusterInstallation.ID, "mmctl", args)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, errors.Wrapf(err, "failed to run %s: %s", strings.Join(args[:2], " "), strings.TrimSpace(string(output))))
+		return
+	}
+
+	result := &UserActionResult{
+		Action: actionRequest.Action,
+		Output: strings.TrimSpace(string(output)),
+	}
+
+	users, err = execUserCommand(r.Context(), c.CloudClient, clusterInstallation.ID, []string{"user", "search", "--local", "--json", user.ID})
+	if err != nil {
+		c.Logger.WithError(err).Warn("Failed to fetch user after action")
+	} else if len(users) > 0 {
+		result.User = users[0]
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestWorkspaceUserAction(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("customer cannot log in", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+
+	expectUser := func(userArg string, output string) *gomock.Call {
+		return mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "search", "--local", "--json", userArg})).
+			Times(1).
+			Return([]byte(output), nil)
+	}
+	expectWorkspace := func() {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+	}
+
+	t.Run("verify email", func(t *testing.T) {
+		expectWorkspace()
+		gomock.InOrder(
+			expectUser("alice@example.com", `{"id":"userid","email":"alice@example.com"}`),
+			mockCloudClient.EXPECT().
+				ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "verify", "--local", "userid"})).
+				Times(1).
+				Return([]byte("User userid verified\n"), nil),
+			expectUser("userid", `{"id":"userid","email":"alice@example.com","email_verified":true}`),
+		)
+
+		result, err := client.RunWorkspaceUserAction("installationid", "alice@example.com", &UserActionRequest{Action: UserActionVerifyEmail})
+		require.NoError(t, err)
+		assert.Equal(t, UserActionVerifyEmail, result.Action)
+		assert.Equal(t, "User userid verified", result.Output)
+		require.NotNil(t, result.User)
+		assert.True(t, result.User.EmailVerified)
+	})
+
+	t.Run("revoke sessions is not supported", func(t *testing.T) {
+		result, err := client.RunWorkspaceUserAction("installationid", "alice", &UserActionRequest{Action: UserActionRevokeSessions})
+		assert.EqualError(t, err, "failed with status code 501: revoking sessions is not supported, as mmctl cannot revoke sessions and Pillar holds no credentials for the workspace API; deactivating the user revokes their sessions")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		result, err := client.RunWorkspaceUserAction("installationid", "alice", &UserActionRequest{Action: "delete"})
+		assert.EqualError(t, err, "failed with status code 400: invalid action \"delete\", must be one of [reset_password reset_mfa verify_email activate deactivate promote_system_admin]")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid user", func(t *testing.T) {
+		result, err := client.RunWorkspaceUserAction("installationid", "-alice", &UserActionRequest{Action: UserActionResetMFA})
+		assert.EqualError(t, err, "failed with status code 400: invalid user ID, email or username \"-alice\"")
+		assert.Nil(t, result)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		expectWorkspace()
+		expectUser("missing", "Unable to find user 'missing'\n")
+
+		result, err := client.RunWorkspaceUserAction("installationid", "missing", &UserActionRequest{Action: UserActionResetMFA})
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, result)
+	})
+
+	t.Run("error running action", func(t *testing.T) {
+		expectWorkspace()
+		gomock.InOrder(
+			expectUser("alice", `{"id":"userid"}`),
+			mockCloudClient.EXPECT().
+				ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "reset_password", "--local", "userid"})).
+				Times(1).
+				Return([]byte("SMTP is not configured\n"), errors.New("some error")),
+		)
+
+		result, err := client.RunWorkspaceUserAction("installationid", "alice", &UserActionRequest{Action: UserActionResetPassword})
+		assert.EqualError(t, err, "failed with status code 500: failed to run user reset_password: SMTP is not configured: some error")
+		assert.Nil(t, result)
+	})
+}
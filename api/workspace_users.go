@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// User is a Mattermost user of a workspace. It only holds the fields useful
+// to support, never password hashes or the data of external authentication.
+type User struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Nickname      string `json:"nickname"`
+	Position      string `json:"position"`
+	Roles         string `json:"roles"`
+	Locale        string `json:"locale"`
+	// AuthService is the method the user logs in with, such as saml or gitlab,
+	// or empty for email and password.
+	AuthService        string `json:"auth_service"`
+	MfaActive          bool   `json:"mfa_active"`
+	FailedAttempts     int    `json:"failed_attempts"`
+	LastPasswordUpdate int64  `json:"last_password_update"`
+	IsBot              bool   `json:"is_bot"`
+	CreateAt           int64  `json:"create_at"`
+	UpdateAt           int64  `json:"update_at"`
+	// DeleteAt is the time the user was deactivated, or 0 if active.
+	DeleteAt int64 `json:"delete_at"`
+}
+
+// handleListWorkspaceUsers responds to GET /api/v1/workspaces/{id}/users, listing the given page of users of the
+// workspace with mmctl.
+//
+// If the q parameter is given, each of its words is instead looked up exactly as a user ID, email or username, and
+// the users found are returned. This is not a substring search, and cannot be combined with paging.
+func handleListWorkspaceUsers(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	c.Logger = c.Logger.WithField("workspace", workspaceID)
+
+	query := r.URL.Query()
+	page, perPage, err := parsePaging(query)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	args := []string{"user", "list", "--local", "--json", "--page", strconv.Itoa(page), "--per-page", strconv.Itoa(perPage)}
+	if words := strings.Fields(query.Get("q")); len(words) > 0 {
+		if query.Get("page") != "" || query.Get("per_page") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			c.writeAndLogError(w, errors.New("q looks users up exactly and cannot be combined with page or per_page"))
+			return
+		}
+		for _, word := range words {
+			if !userRefPattern.MatchString(word) {
+				w.WriteHeader(http.StatusBadRequest)
+				c.writeAndLogError(w, errors.Errorf("invalid user ID, email or username %q", word))
+				return
+			}
+		}
+		args = append([]string{"user", "search", "--local", "--json"}, words...)
+	}
+
+	users, ok := getWorkspaceUsers(c, w, r, workspaceID, args)
+	if !ok {
+		return
+	}
+
+	b, err := json.Marshal(users)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleGetWorkspaceUser responds to GET /api/v1/workspaces/{id}/users/{user}, fetching a single user of the
+// workspace by ID, email or username with mmctl.
+func handleGetWorkspaceUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	workspaceID := vars["workspace"]
+	userID := vars["user"]
+	c.Logger = c.Logger.WithFields(logrus.Fields{"workspace": workspaceID, "user": userID})
+
+	if !userRefPattern.MatchString(userID) {
+		w.WriteHeader(http.StatusBadRequest)
+		c.writeAndLogError(w, errors.Errorf("invalid user ID, email or username %q", userID))
+		return
+	}
+
+	users, ok := getWorkspaceUsers(c, w, r, workspaceID, []string{"user", "search", "--local", "--json", userID})
+	if !ok {
+		return
+	}
+	if len(users) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	b, err := json.Marshal(users[0])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// getWorkspaceUsers runs the given mmctl user command against the workspace,
+// responding with the appropriate error and returning false if it fails.
+func getWorkspaceUsers(c *Context, w http.ResponseWriter, r *http.Request, workspaceID string, args []string) ([]*User, bool) {
+	clusterInstallation, ok := getWorkspaceClusterInstallation(c, w, r, workspaceID)
+	if !ok {
+		return nil, false
+	}
+
+	users, err := execUserCommand(r.Context(), c.CloudClient, clusterInstallation.ID, args)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		c.writeAndLogError(w, err)
+		return nil, false
+	}
+
+	return users, true
+}
+
+// execUserCommand runs an mmctl command printing users as JSON against the
+// cluster installation, returning the users.
+func execUserCommand(ctx context.Context, client CloudClient, clusterInstallationID string, args []string) ([]*User, error) {
+	values, err := execMmctlJSON(ctx, client, clusterInstallationID, args)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []*User{}
+	for _, value := range values {
+		user := &User{}
+		err = json.Unmarshal(value, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode user")
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+
+	"github.com/mattermost/pillar/mock"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestDecodeMmctlJSON(t *testing.T) {
+	t.Run("array", func(t *testing.T) {
+		values, err := decodeMmctlJSON([]byte(`[{"id":"user1"},{"id":"user2"}]`))
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("objects between messages", func(t *testing.T) {
+		output := []byte("{\n  \"id\": \"user1\"\n}\nUnable to find user 'missing'\n{\"id\":\"user2\"}\n")
+		values, err := decodeMmctlJSON(output)
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+		assert.JSONEq(t, `{"id":"user2"}`, string(values[1]))
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		values, err := decodeMmctlJSON([]byte("There are no users\n"))
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := decodeMmctlJSON([]byte(`{"id":`))
+		assert.Error(t, err)
+	})
+}
+
+func TestWorkspaceUsers(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	ctrl := gomock.NewController(t)
+	mockCloudClient := mock.NewMockCloudClient(ctrl)
+
+	router := mux.NewRouter()
+	Register(router, &Context{
+		Logger:      logger,
+		CloudClient: mockCloudClient,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := NewClient(ts.URL)
+	client.SetJustification("customer cannot log in", "")
+
+	mockInstallation := &cloud.InstallationDTO{Installation: &cloud.Installation{ID: "installationid"}}
+	mockClusterInstallations := []*cloud.ClusterInstallation{{ID: "clusterinstallationid", State: cloud.ClusterInstallationStateStable}}
+	userOutput := []byte(`{"id":"userid","username":"alice","email":"alice@example.com","password":"$2a$10$hash","auth_data":"samlid","auth_service":"saml","failed_attempts":3}`)
+
+	t.Run("list users", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "list", "--local", "--json", "--page", "1", "--per-page", "10"})).
+			Times(1).
+			Return([]byte(`[{"id":"user1"},{"id":"user2"}]`), nil)
+
+		users, err := client.ListWorkspaceUsers("installationid", "", 1, 10)
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+	})
+
+	t.Run("search users", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "search", "--local", "--json", "alice", "bob"})).
+			Times(1).
+			Return([]byte("There are no users matching 'bob'\n"), nil)
+
+		users, err := client.ListWorkspaceUsers("installationid", "alice bob", 0, 0)
+		require.NoError(t, err)
+		assert.NotNil(t, users)
+		assert.Empty(t, users)
+	})
+
+	t.Run("get user", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().
+			ExecClusterInstallationCLI(gomock.Any(), gomock.Eq("clusterinstallationid"), gomock.Eq("mmctl"), gomock.Eq([]string{"user", "search", "--local", "--json", "alice@example.com"})).
+			Times(1).
+			Return(userOutput, nil)
+
+		user, err := client.GetWorkspaceUser("installationid", "alice@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, &User{ID: "userid", Username: "alice", Email: "alice@example.com", AuthService: "saml", FailedAttempts: 3}, user)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return([]byte("Unable to find user 'missing'\n"), nil)
+
+		user, err := client.GetWorkspaceUser("installationid", "missing")
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, user)
+	})
+
+	t.Run("workspace not found", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(nil, nil)
+
+		users, err := client.ListWorkspaceUsers("installationid", "", 0, 0)
+		assert.EqualError(t, err, "failed with status code 404")
+		assert.Nil(t, users)
+	})
+
+	t.Run("invalid paging", func(t *testing.T) {
+		users, err := client.ListWorkspaceUsers("installationid", "", -1, 0)
+		assert.EqualError(t, err, "failed with status code 400: page must not be negative")
+		assert.Nil(t, users)
+	})
+
+	t.Run("search with paging", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/v1/workspaces/installationid/users?q=alice&page=1", nil)
+		require.NoError(t, err)
+		req.Header.Set(HeaderJustification, "customer cannot log in")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("invalid search", func(t *testing.T) {
+		users, err := client.ListWorkspaceUsers("installationid", "alice --help", 0, 0)
+		assert.EqualError(t, err, "failed with status code 400: invalid user ID, email or username \"--help\"")
+		assert.Nil(t, users)
+	})
+
+	t.Run("invalid user", func(t *testing.T) {
+		user, err := client.GetWorkspaceUser("installationid", "--help")
+		assert.EqualError(t, err, "failed with status code 400: invalid user ID, email or username \"--help\"")
+		assert.Nil(t, user)
+	})
+
+	t.Run("error running mmctl", func(t *testing.T) {
+		mockCloudClient.EXPECT().GetInstallation(gomock.Any(), gomock.Eq("installationid"), gomock.Any()).Times(1).Return(mockInstallation, nil)
+		mockCloudClient.EXPECT().GetClusterInstallations(gomock.Any(), gomock.Any()).Times(1).Return(mockClusterInstallations, nil)
+		mockCloudClient.EXPECT().ExecClusterInstallationCLI(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		user, err := client.GetWorkspaceUser("installationid", "alice")
+		assert.EqualError(t, err, "failed with status code 500: some error")
+		assert.Nil(t, user)
+	})
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/pillar/api"
+)
+
+func init() {
+	viper.SetEnvPrefix("PILLAR")
+	viper.AutomaticEnv()
+
+	auditCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The pillar server whose API will be queried.")
+	auditCmd.PersistentFlags().String("token", viper.GetString("TOKEN"), "The API token or OIDC issued JWT used to authenticate with the pillar server | ENV: PILLAR_TOKEN")
+
+	auditListCmd.Flags().String("actor", "", "The actor by which to filter audit records.")
+	auditListCmd.Flags().String("workspace", "", "The workspace ID by which to filter audit records.")
+	auditListCmd.Flags().String("since", "", "Only list records created at or after this time, given as RFC3339 or as a duration before now such as 24h.")
+	auditListCmd.Flags().String("until", "", "Only list records created before this time, given as RFC3339 or as a duration before now such as 24h.")
+	auditListCmd.Flags().Int("page", 0, "The page of audit records to fetch, starting at 0.")
+	auditListCmd.Flags().Int("per-page", 100, "The number of audit records to fetch per page.")
+	auditCmd.AddCommand(auditListCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "View the audit log of support actions.",
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit records.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		actor, _ := command.Flags().GetString("actor")
+		workspace, _ := command.Flags().GetString("workspace")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+
+		since, err := getTimeFlagMillis(command, "since")
+		if err != nil {
+			return err
+		}
+		until, err := getTimeFlagMillis(command, "until")
+		if err != nil {
+			return err
+		}
+
+		records, err := client.GetAuditRecords(&api.AuditFilter{
+			Actor:       actor,
+			WorkspaceID: workspace,
+			Since:       since,
+			Until:       until,
+			Page:        page,
+			PerPage:     perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query audit records")
+		}
+
+		err = printJSON(records)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+// getTimeFlagMillis parses the named flag as either an RFC3339 time or a
+// duration before now, returning milliseconds since the epoch or 0 if unset.
+func getTimeFlagMillis(command *cobra.Command, name string) (int64, error) {
+	value, _ := command.Flags().GetString(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	var t time.Time
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		t = time.Now().Add(-duration)
+	} else {
+		t, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return 0, errors.Errorf("%s must be an RFC3339 time or a duration", name)
+		}
+	}
+
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
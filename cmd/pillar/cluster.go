@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+func init() {
+	viper.SetEnvPrefix("PILLAR")
+	viper.AutomaticEnv()
+
+	clusterCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The pillar server whose API will be queried.")
+	clusterCmd.PersistentFlags().String("token", viper.GetString("TOKEN"), "The API token or OIDC issued JWT used to authenticate with the pillar server | ENV: PILLAR_TOKEN")
+	clusterCmd.PersistentFlags().Bool("refresh", false, "Whether to bypass the cache of the server and fetch fresh data from the provisioner.")
+
+	clusterListCmd.Flags().Int("page", 0, "The page of clusters to fetch, starting at 0.")
+	clusterListCmd.Flags().Int("per-page", 100, "The number of clusters to fetch per page.")
+	clusterListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted clusters.")
+	clusterCmd.AddCommand(clusterListCmd)
+
+	clusterWorkspacesCmd.Flags().String("id", "", "ID of the cluster whose workspaces to list.")
+	clusterWorkspacesCmd.Flags().Int("page", 0, "The page of workspaces to fetch, starting at 0.")
+	clusterWorkspacesCmd.Flags().Int("per-page", 100, "The number of workspaces to fetch per page.")
+	clusterWorkspacesCmd.Flags().Bool("include-deleted", false, "Whether to include deleted cluster installations.")
+	clusterWorkspacesCmd.MarkFlagRequired("id")
+	clusterCmd.AddCommand(clusterWorkspacesCmd)
+}
+
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "View the clusters hosting workspaces.",
+}
+
+var clusterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List clusters.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		clusters, err := client.ListClusters(&cloud.GetClustersRequest{
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query clusters")
+		}
+
+		err = printJSON(clusters)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var clusterWorkspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "List the workspaces hosted on a cluster.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		clusterID, _ := command.Flags().GetString("id")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		workspaces, err := client.ListClusterWorkspaces(clusterID, &cloud.GetClusterInstallationsRequest{
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query cluster workspaces")
+		}
+
+		err = printJSON(workspaces)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
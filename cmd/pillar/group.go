@@ -0,0 +1,121 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cloud "github.com/mattermost/mattermost-cloud/model"
+)
+
+func init() {
+	viper.SetEnvPrefix("PILLAR")
+	viper.AutomaticEnv()
+
+	groupCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The pillar server whose API will be queried.")
+	groupCmd.PersistentFlags().String("token", viper.GetString("TOKEN"), "The API token or OIDC issued JWT used to authenticate with the pillar server | ENV: PILLAR_TOKEN")
+	groupCmd.PersistentFlags().Bool("refresh", false, "Whether to bypass the cache of the server and fetch fresh data from the provisioner.")
+
+	groupListCmd.Flags().Int("page", 0, "The page of groups to fetch, starting at 0.")
+	groupListCmd.Flags().Int("per-page", 100, "The number of groups to fetch per page.")
+	groupListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted groups.")
+	groupCmd.AddCommand(groupListCmd)
+
+	groupGetCmd.Flags().String("id", "", "ID of the group to get.")
+	groupGetCmd.MarkFlagRequired("id")
+	groupCmd.AddCommand(groupGetCmd)
+
+	groupWorkspacesCmd.Flags().String("id", "", "ID of the group whose workspaces to list.")
+	groupWorkspacesCmd.Flags().Int("page", 0, "The page of workspaces to fetch, starting at 0.")
+	groupWorkspacesCmd.Flags().Int("per-page", 100, "The number of workspaces to fetch per page.")
+	groupWorkspacesCmd.Flags().Bool("include-deleted", false, "Whether to include deleted workspaces.")
+	groupWorkspacesCmd.MarkFlagRequired("id")
+	groupCmd.AddCommand(groupWorkspacesCmd)
+}
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "View groups of workspaces.",
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List groups.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		groups, err := client.ListGroups(&cloud.GetGroupsRequest{
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query groups")
+		}
+
+		err = printJSON(groups)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var groupGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a group and the configuration it applies to its workspaces.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		groupID, _ := command.Flags().GetString("id")
+		group, err := client.GetGroup(groupID)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch group")
+		}
+
+		err = printJSON(group)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var groupWorkspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "List the workspaces that belong to a group.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		groupID, _ := command.Flags().GetString("id")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		workspaces, err := client.ListGroupWorkspaces(groupID, &cloud.GetInstallationsRequest{
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query group workspaces")
+		}
+
+		err = printJSON(workspaces)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
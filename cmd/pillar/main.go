@@ -21,6 +21,10 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(serverCmd)
 	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(groupCmd)
+	rootCmd.AddCommand(clusterCmd)
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(auditCmd)
 }
 
 func main() {
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	viper.SetEnvPrefix("PILLAR")
+	viper.AutomaticEnv()
+
+	pluginCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The pillar server whose API will be queried.")
+	pluginCmd.PersistentFlags().String("token", viper.GetString("TOKEN"), "The API token or OIDC issued JWT used to authenticate with the pillar server | ENV: PILLAR_TOKEN")
+
+	pluginWorkspacesCmd.Flags().String("plugin", "", "ID of the plugin to search workspaces for.")
+	pluginWorkspacesCmd.Flags().String("version", "", "Only find workspaces running this version of the plugin.")
+	pluginWorkspacesCmd.Flags().Int("page", 0, "The page of cluster installations to search, starting at 0.")
+	pluginWorkspacesCmd.Flags().Int("per-page", 100, "The number of cluster installations to search per page, at most 100.")
+	pluginWorkspacesCmd.MarkFlagRequired("plugin")
+	pluginCmd.AddCommand(pluginWorkspacesCmd)
+}
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Find the workspaces running plugins.",
+}
+
+var pluginWorkspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "Find the workspaces that have a plugin installed.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		pluginID, _ := command.Flags().GetString("plugin")
+		version, _ := command.Flags().GetString("version")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		pluginWorkspaces, err := client.ListPluginWorkspaces(pluginID, version, page, perPage)
+		if err != nil {
+			return errors.Wrap(err, "failed to search plugin workspaces")
+		}
+
+		err = printJSON(pluginWorkspaces)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{"unset", "", []string{}},
+		{"single", "10.0.0.0/8", []string{"10.0.0.0/8"}},
+		{"comma separated", "10.0.0.0/8,192.168.0.0/16", []string{"10.0.0.0/8", "192.168.0.0/16"}},
+		{"spaces around values", " alice=token1 , bob=token2 ", []string{"alice=token1", "bob=token2"}},
+		{"empty values", "10.0.0.0/8,,", []string{"10.0.0.0/8"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv("PILLAR_TEST_LIST", tc.value)
+			defer os.Unsetenv("PILLAR_TEST_LIST")
+
+			assert.Equal(t, tc.expected, envList("TEST_LIST"))
+		})
+	}
+}
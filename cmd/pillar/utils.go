@@ -1,12 +1,66 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/pillar/api"
 )
 
+// prompt asks the user for a line of input, returning it without surrounding whitespace.
+func prompt(message string) (string, error) {
+	fmt.Fprint(os.Stderr, message)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
 func printJSON(data interface{}) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "    ")
 	return encoder.Encode(data)
 }
+
+// getStringFlagPointer returns a pointer to the value of the named flag, or
+// nil if the flag was not explicitly set.
+func getStringFlagPointer(command *cobra.Command, name string) *string {
+	if !command.Flags().Changed(name) {
+		return nil
+	}
+
+	value, _ := command.Flags().GetString(name)
+	return &value
+}
+
+// newClient creates an API client for the server, credentials and
+// justification given by the command flags.
+func newClient(command *cobra.Command) *api.Client {
+	serverAddress, _ := command.Flags().GetString("server")
+	token, _ := command.Flags().GetString("token")
+
+	var client *api.Client
+	if token == "" {
+		client = api.NewClient(serverAddress)
+	} else {
+		client = api.NewClientWithToken(serverAddress, token)
+	}
+
+	reason, _ := command.Flags().GetString("reason")
+	ticket, _ := command.Flags().GetString("ticket")
+	client.SetJustification(reason, ticket)
+
+	refresh, _ := command.Flags().GetBool("refresh")
+	client.SetRefresh(refresh)
+
+	return client
+}
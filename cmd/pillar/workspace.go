@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,6 +19,10 @@ func init() {
 	viper.AutomaticEnv()
 
 	workspaceCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The pillar server whose API will be queried.")
+	workspaceCmd.PersistentFlags().String("token", viper.GetString("TOKEN"), "The API token or OIDC issued JWT used to authenticate with the pillar server | ENV: PILLAR_TOKEN")
+	workspaceCmd.PersistentFlags().Bool("refresh", false, "Whether to bypass the cache of the server and fetch fresh data from the provisioner.")
+	workspaceCmd.PersistentFlags().String("reason", "", "The reason for accessing the workspace. Required to view or change a workspace.")
+	workspaceCmd.PersistentFlags().String("ticket", "", "The support ticket the workspace is accessed for.")
 
 	workspaceListCmd.Flags().String("owner", "", "The owner by which to filter workspaces.")
 	workspaceListCmd.Flags().String("group", "", "The group ID by which to filter workspaces.")
@@ -22,11 +30,134 @@ func init() {
 	workspaceListCmd.Flags().Int("per-page", 100, "The number of workspaces to fetch per page.")
 	workspaceListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted workspaces.")
 	workspaceListCmd.Flags().String("dns", "", "The dns to filter results by.")
+	workspaceListCmd.Flags().String("state", "", "The state to filter results by, e.g. stable or hibernating.")
 	workspaceCmd.AddCommand(workspaceListCmd)
 
+	workspaceSearchCmd.Flags().String("query", "", "Matches workspaces by ID, or by DNS containing every word of the query, e.g. a partial company name.")
+	workspaceSearchCmd.Flags().String("state", "", "The state by which to filter workspaces, e.g. stable or hibernating.")
+	workspaceSearchCmd.Flags().String("version", "", "The version by which to filter workspaces.")
+	workspaceSearchCmd.Flags().String("size", "", "The size by which to filter workspaces.")
+	workspaceSearchCmd.Flags().String("edition", "", "The edition by which to filter workspaces.")
+	workspaceSearchCmd.Flags().String("database", "", "The database type by which to filter workspaces.")
+	workspaceSearchCmd.Flags().String("filestore", "", "The filestore type by which to filter workspaces.")
+	workspaceSearchCmd.Flags().String("sort", api.WorkspaceSortDNS, fmt.Sprintf("The field to sort workspaces by. Accepts %s.", strings.Join(api.WorkspaceSortFields, ", ")))
+	workspaceSearchCmd.Flags().Bool("desc", false, "Whether to sort workspaces in descending order.")
+	workspaceSearchCmd.Flags().Int("page", 0, "The page of workspaces to fetch, starting at 0.")
+	workspaceSearchCmd.Flags().Int("per-page", 100, "The number of workspaces to fetch per page.")
+	workspaceSearchCmd.Flags().Bool("include-deleted", false, "Whether to include deleted workspaces.")
+	workspaceCmd.AddCommand(workspaceSearchCmd)
+
 	workspaceGetCmd.Flags().String("id", "", "ID of the workspace to get.")
+	workspaceGetCmd.Flags().Bool("unredacted", false, "Whether to include the cleartext values of sensitive config settings. Must be allowed by the server.")
 	workspaceGetCmd.MarkFlagRequired("id")
 	workspaceCmd.AddCommand(workspaceGetCmd)
+
+	workspaceUpdateCmd.Flags().String("id", "", "ID of the workspace to update.")
+	workspaceUpdateCmd.Flags().String("version", "", "The Mattermost version to update the workspace to. It must be a tag of the image of the workspace.")
+	workspaceUpdateCmd.Flags().String("image", "", "The Mattermost container image to update the workspace to.")
+	workspaceUpdateCmd.Flags().String("size", "", fmt.Sprintf("The size to update the workspace to. Accepts %s.", strings.Join(api.AllowedWorkspaceSizes, ", ")))
+	workspaceUpdateCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceUpdateCmd)
+
+	workspaceDeleteCmd.Flags().String("id", "", "ID of the workspace to delete.")
+	workspaceDeleteCmd.Flags().String("dns", "", "The DNS of the workspace, confirming its deletion. Required with --yes.")
+	workspaceDeleteCmd.Flags().Bool("yes", false, "Delete the workspace without prompting for confirmation, which is then given by --dns.")
+	workspaceDeleteCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceDeleteCmd)
+
+	workspaceHibernateCmd.Flags().String("id", "", "ID of the workspace to hibernate.")
+	workspaceHibernateCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceHibernateCmd)
+
+	workspaceWakeCmd.Flags().String("id", "", "ID of the workspace to wake up.")
+	workspaceWakeCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceWakeCmd)
+
+	workspaceUsersCmd.Flags().String("id", "", "ID of the workspace whose users to look up.")
+	workspaceUsersCmd.Flags().String("user", "", "The ID, email or username of a single user to get.")
+	workspaceUsersCmd.Flags().String("query", "", "Space separated IDs, emails or usernames of the users to find. Each must match exactly, and cannot be combined with paging.")
+	workspaceUsersCmd.Flags().Int("page", 0, "The page of users to list.")
+	workspaceUsersCmd.Flags().Int("per-page", 100, "The number of users to list per page.")
+	workspaceUsersCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceUsersCmd)
+
+	workspaceTeamsCmd.Flags().String("id", "", "ID of the workspace whose teams to list.")
+	workspaceTeamsCmd.Flags().String("query", "", "Only list teams whose name or display name contains every word of the query.")
+	workspaceTeamsCmd.Flags().Bool("member-counts", false, "Count the members of each listed team, which takes a command per team.")
+	workspaceTeamsCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceTeamsCmd)
+
+	workspaceChannelsCmd.Flags().String("id", "", "ID of the workspace whose channels to look up.")
+	workspaceChannelsCmd.Flags().String("team", "", "The ID or name of the team whose channels to list.")
+	workspaceChannelsCmd.Flags().String("channel", "", "The ID of a single channel to get, or its name together with --team.")
+	workspaceChannelsCmd.Flags().String("query", "", "Only list channels whose name or display name contains every word of the query.")
+	workspaceChannelsCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceChannelsCmd)
+
+	for _, action := range []struct {
+		use    string
+		short  string
+		action string
+	}{
+		{"reset-password", "Send a workspace user a password reset email.", api.UserActionResetPassword},
+		{"reset-mfa", "Turn off multi-factor authentication for a workspace user.", api.UserActionResetMFA},
+		{"verify-email", "Mark the email of a workspace user as verified.", api.UserActionVerifyEmail},
+		{"activate", "Activate a deactivated workspace user.", api.UserActionActivate},
+		{"deactivate", "Deactivate a workspace user.", api.UserActionDeactivate},
+		{"promote", "Make a workspace user a system admin.", api.UserActionPromoteSystemAdmin},
+	} {
+		workspaceUserCmd.AddCommand(newWorkspaceUserActionCmd(action.use, action.short, action.action))
+	}
+	workspaceCmd.AddCommand(workspaceUserCmd)
+
+	for _, command := range []*cobra.Command{workspaceChannelUnarchiveCmd, workspaceChannelMoveCmd, workspaceChannelRenameCmd} {
+		command.Flags().String("id", "", "ID of the workspace of the channel.")
+		command.Flags().String("channel", "", "The ID of the channel, or its name together with --team.")
+		command.Flags().String("team", "", "The ID or name of the team of the channel, when given by name.")
+		command.MarkFlagRequired("id")
+		command.MarkFlagRequired("channel")
+		workspaceChannelCmd.AddCommand(command)
+	}
+	workspaceChannelMoveCmd.Flags().String("to-team", "", "The ID or name of the team to move the channel to.")
+	workspaceChannelMoveCmd.MarkFlagRequired("to-team")
+	workspaceChannelRenameCmd.Flags().String("name", "", "The new name of the channel, as used in its URL.")
+	workspaceChannelRenameCmd.Flags().String("display-name", "", "The new display name of the channel.")
+	workspaceCmd.AddCommand(workspaceChannelCmd)
+
+	workspaceDoctorCmd.Flags().String("id", "", "ID of the workspace to check.")
+	workspaceDoctorCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceDoctorCmd)
+
+	workspaceSupportPacketCmd.Flags().String("id", "", "ID of the workspace to gather a support packet of.")
+	workspaceSupportPacketCmd.Flags().StringP("output", "o", "", "The file to write the support packet zip to. Defaults to <id>-support-packet.zip.")
+	workspaceSupportPacketCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspaceSupportPacketCmd)
+
+	workspacePluginsCmd.Flags().String("id", "", "ID of the workspace whose plugins to list.")
+	workspacePluginsCmd.MarkFlagRequired("id")
+	workspaceCmd.AddCommand(workspacePluginsCmd)
+
+	for _, action := range []struct {
+		use    string
+		short  string
+		action string
+	}{
+		{"enable", "Enable an installed workspace plugin.", api.PluginActionEnable},
+		{"disable", "Disable an installed workspace plugin.", api.PluginActionDisable},
+		{"install", "Install a plugin on a workspace from the marketplace.", api.PluginActionInstall},
+	} {
+		workspacePluginCmd.AddCommand(newWorkspacePluginActionCmd(action.use, action.short, action.action))
+	}
+	workspaceCmd.AddCommand(workspacePluginCmd)
+
+	workspaceConfigSetCmd.Flags().String("id", "", "ID of the workspace whose config will be changed.")
+	workspaceConfigSetCmd.Flags().String("key", "", "The dot separated config setting to change, e.g. ServiceSettings.SiteURL. Settings holding secrets cannot be changed.")
+	workspaceConfigSetCmd.Flags().StringArray("value", []string{}, "The value to set. Repeat the flag to set a list value.")
+	workspaceConfigSetCmd.MarkFlagRequired("id")
+	workspaceConfigSetCmd.MarkFlagRequired("key")
+	workspaceConfigSetCmd.MarkFlagRequired("value")
+	workspaceConfigCmd.AddCommand(workspaceConfigSetCmd)
+	workspaceCmd.AddCommand(workspaceConfigCmd)
 }
 
 var workspaceCmd = &cobra.Command{
@@ -40,8 +171,7 @@ var workspaceListCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := api.NewClient(serverAddress)
+		client := newClient(command)
 
 		owner, _ := command.Flags().GetString("owner")
 		group, _ := command.Flags().GetString("group")
@@ -49,16 +179,28 @@ var workspaceListCmd = &cobra.Command{
 		perPage, _ := command.Flags().GetInt("per-page")
 		includeDeleted, _ := command.Flags().GetBool("include-deleted")
 		dns, _ := command.Flags().GetString("dns")
-		workspaces, err := client.ListWorkspaces(&cloud.GetInstallationsRequest{
-			OwnerID:                     owner,
-			GroupID:                     group,
-			IncludeGroupConfig:          true,
-			IncludeGroupConfigOverrides: false,
-			Page:                        page,
-			PerPage:                     perPage,
-			DNS:                         dns,
-			IncludeDeleted:              includeDeleted,
-		})
+		state, _ := command.Flags().GetString("state")
+
+		request := &api.ListWorkspacesRequest{
+			GetInstallationsRequest: cloud.GetInstallationsRequest{
+				OwnerID:                     owner,
+				GroupID:                     group,
+				IncludeGroupConfig:          true,
+				IncludeGroupConfigOverrides: false,
+				Page:                        page,
+				PerPage:                     perPage,
+				DNS:                         dns,
+				IncludeDeleted:              includeDeleted,
+			},
+			State: state,
+		}
+
+		err := request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid list")
+		}
+
+		workspaces, err := client.ListWorkspacesWithState(request)
 		if err != nil {
 			return errors.Wrap(err, "failed to query workspaces")
 		}
@@ -72,20 +214,143 @@ var workspaceListCmd = &cobra.Command{
 	},
 }
 
+var workspaceSearchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search workspaces by partial DNS and properties.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		search := &api.WorkspaceSearch{}
+		search.Query, _ = command.Flags().GetString("query")
+		search.State, _ = command.Flags().GetString("state")
+		search.Version, _ = command.Flags().GetString("version")
+		search.Size, _ = command.Flags().GetString("size")
+		search.Edition, _ = command.Flags().GetString("edition")
+		search.Database, _ = command.Flags().GetString("database")
+		search.Filestore, _ = command.Flags().GetString("filestore")
+		search.Sort, _ = command.Flags().GetString("sort")
+		search.Descending, _ = command.Flags().GetBool("desc")
+		search.Page, _ = command.Flags().GetInt("page")
+		search.PerPage, _ = command.Flags().GetInt("per-page")
+		search.IncludeDeleted, _ = command.Flags().GetBool("include-deleted")
+
+		err := search.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid search")
+		}
+
+		workspaces, err := client.SearchWorkspaces(search)
+		if err != nil {
+			return errors.Wrap(err, "failed to search workspaces")
+		}
+
+		err = printJSON(workspaces)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
 var workspaceGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get a workspace.",
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
-		serverAddress, _ := command.Flags().GetString("server")
-		client := api.NewClient(serverAddress)
+		client := newClient(command)
 
 		workspaceID, _ := command.Flags().GetString("id")
-		workspace, err := client.GetWorkspace(workspaceID)
+		unredacted, _ := command.Flags().GetBool("unredacted")
+
+		var workspace *api.WorkspaceDetailed
+		var err error
+		if unredacted {
+			workspace, err = client.GetWorkspaceUnredacted(workspaceID)
+		} else {
+			workspace, err = client.GetWorkspace(workspaceID)
+		}
 		if err != nil {
 			return errors.Wrap(err, "failed to fetch workspace")
 		}
+		for _, sectionErr := range workspace.Errors() {
+			logger.Warn(sectionErr.Error())
+		}
+
+		err = printJSON(workspace)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the version, image or size of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		request := &api.UpdateWorkspaceRequest{
+			Version: getStringFlagPointer(command, "version"),
+			Image:   getStringFlagPointer(command, "image"),
+			Size:    getStringFlagPointer(command, "size"),
+		}
+
+		err := request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid update")
+		}
+
+		workspace, err := client.UpdateWorkspace(workspaceID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to update workspace")
+		}
+
+		err = printJSON(workspace)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a workspace.",
+	Long:  "Delete a workspace. The DNS of the workspace must be typed to confirm the deletion, or passed with --dns along with --yes.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		dns, _ := command.Flags().GetString("dns")
+		yes, _ := command.Flags().GetBool("yes")
+
+		if yes && dns == "" {
+			return errors.New("--dns must be passed with --yes to confirm the deletion")
+		}
+		if !yes {
+			var err error
+			dns, err = prompt(fmt.Sprintf("Deleting a workspace cannot be undone. Type the DNS of workspace %s to confirm: ", workspaceID))
+			if err != nil {
+				return errors.Wrap(err, "failed to read confirmation")
+			}
+		}
+
+		workspace, err := client.DeleteWorkspace(workspaceID, &api.DeleteWorkspaceRequest{ConfirmDNS: dns})
+		if err != nil {
+			return errors.Wrap(err, "failed to delete workspace")
+		}
 
 		err = printJSON(workspace)
 		if err != nil {
@@ -95,3 +360,484 @@ var workspaceGetCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var workspaceUsersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Look up the users of a workspace.",
+	Long:  "Look up the users of a workspace. Gets a single user with --user, finds users with --query, or lists a page of users otherwise.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		user, _ := command.Flags().GetString("user")
+
+		if user != "" {
+			workspaceUser, err := client.GetWorkspaceUser(workspaceID, user)
+			if err != nil {
+				return errors.Wrap(err, "failed to get workspace user")
+			}
+
+			return printJSON(workspaceUser)
+		}
+
+		query, _ := command.Flags().GetString("query")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		if query != "" && (command.Flags().Changed("page") || command.Flags().Changed("per-page")) {
+			return errors.New("--query cannot be combined with --page or --per-page")
+		}
+
+		users, err := client.ListWorkspaceUsers(workspaceID, query, page, perPage)
+		if err != nil {
+			return errors.Wrap(err, "failed to list workspace users")
+		}
+
+		err = printJSON(users)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceTeamsCmd = &cobra.Command{
+	Use:   "teams",
+	Short: "List the teams of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		query, _ := command.Flags().GetString("query")
+		memberCounts, _ := command.Flags().GetBool("member-counts")
+
+		teams, err := client.ListWorkspaceTeams(workspaceID, query, memberCounts)
+		if err != nil {
+			return errors.Wrap(err, "failed to list workspace teams")
+		}
+
+		err = printJSON(teams)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceChannelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Look up the channels of a workspace.",
+	Long:  "Look up the channels of a workspace. Gets a single channel with --channel, or lists the channels of the team given by --team otherwise.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		team, _ := command.Flags().GetString("team")
+		channel, _ := command.Flags().GetString("channel")
+
+		if channel != "" {
+			workspaceChannel, err := client.GetWorkspaceChannel(workspaceID, channel, team)
+			if err != nil {
+				return errors.Wrap(err, "failed to get workspace channel")
+			}
+
+			return printJSON(workspaceChannel)
+		}
+
+		if team == "" {
+			return errors.New("either --team or --channel must be given")
+		}
+
+		query, _ := command.Flags().GetString("query")
+		channels, err := client.ListWorkspaceTeamChannels(workspaceID, team, query)
+		if err != nil {
+			return errors.Wrap(err, "failed to list workspace channels")
+		}
+
+		err = printJSON(channels)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceUserCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Fix the accounts of workspace users.",
+}
+
+// newWorkspaceUserActionCmd creates a command running the given account recovery action.
+func newWorkspaceUserActionCmd(use, short, action string) *cobra.Command {
+	command := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(command *cobra.Command, args []string) error {
+			command.SilenceUsage = true
+
+			client := newClient(command)
+
+			workspaceID, _ := command.Flags().GetString("id")
+			user, _ := command.Flags().GetString("user")
+
+			result, err := client.RunWorkspaceUserAction(workspaceID, user, &api.UserActionRequest{Action: action})
+			if err != nil {
+				return errors.Wrapf(err, "failed to %s", use)
+			}
+
+			err = printJSON(result)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	command.Flags().String("id", "", "ID of the workspace of the user.")
+	command.Flags().String("user", "", "The ID, email or username of the user.")
+	command.MarkFlagRequired("id")
+	command.MarkFlagRequired("user")
+
+	return command
+}
+
+var workspaceChannelCmd = &cobra.Command{
+	Use:   "channel",
+	Short: "Fix the channels of a workspace.",
+}
+
+var workspaceChannelUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive",
+	Short: "Restore an archived workspace channel.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		channel, _ := command.Flags().GetString("channel")
+		team, _ := command.Flags().GetString("team")
+
+		result, err := client.UnarchiveWorkspaceChannel(workspaceID, channel, team)
+		if err != nil {
+			return errors.Wrap(err, "failed to unarchive channel")
+		}
+
+		err = printJSON(result)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceChannelMoveCmd = &cobra.Command{
+	Use:   "move",
+	Short: "Move a workspace channel to another team.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		channel, _ := command.Flags().GetString("channel")
+		team, _ := command.Flags().GetString("team")
+		toTeam, _ := command.Flags().GetString("to-team")
+
+		request := &api.MoveChannelRequest{Team: toTeam}
+		err := request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid channel move")
+		}
+
+		result, err := client.MoveWorkspaceChannel(workspaceID, channel, team, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to move channel")
+		}
+
+		err = printJSON(result)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceChannelRenameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Change the name or display name of a workspace channel.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		channel, _ := command.Flags().GetString("channel")
+		team, _ := command.Flags().GetString("team")
+
+		request := &api.RenameChannelRequest{}
+		if command.Flags().Changed("name") {
+			name, _ := command.Flags().GetString("name")
+			request.Name = &name
+		}
+		if command.Flags().Changed("display-name") {
+			displayName, _ := command.Flags().GetString("display-name")
+			request.DisplayName = &displayName
+		}
+
+		err := request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid channel rename")
+		}
+
+		result, err := client.RenameWorkspaceChannel(workspaceID, channel, team, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to rename channel")
+		}
+
+		err = printJSON(result)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Run the health checks of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		health, err := client.GetWorkspaceHealth(workspaceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check workspace health")
+		}
+
+		err = printJSON(health)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceSupportPacketCmd = &cobra.Command{
+	Use:   "support-packet",
+	Short: "Download a support packet of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		output, _ := command.Flags().GetString("output")
+		if output == "" {
+			output = workspaceID + "-support-packet.zip"
+		}
+
+		file, err := os.Create(output)
+		if err != nil {
+			return errors.Wrap(err, "failed to create support packet file")
+		}
+
+		err = client.DownloadWorkspaceSupportPacket(workspaceID, file)
+		if err != nil {
+			file.Close()
+			os.Remove(output)
+			return errors.Wrap(err, "failed to download support packet")
+		}
+
+		err = file.Close()
+		if err != nil {
+			return errors.Wrap(err, "failed to write support packet file")
+		}
+
+		fmt.Fprintf(os.Stderr, "Support packet written to %s\n", output)
+
+		return nil
+	},
+}
+
+var workspacePluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List the enabled and disabled plugins of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		plugins, err := client.ListWorkspacePlugins(workspaceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list workspace plugins")
+		}
+
+		err = printJSON(plugins)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspacePluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage the plugins of a workspace.",
+}
+
+// newWorkspacePluginActionCmd creates a command running the given plugin action.
+func newWorkspacePluginActionCmd(use, short, action string) *cobra.Command {
+	command := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(command *cobra.Command, args []string) error {
+			command.SilenceUsage = true
+
+			client := newClient(command)
+
+			workspaceID, _ := command.Flags().GetString("id")
+			pluginID, _ := command.Flags().GetString("plugin")
+
+			request := &api.PluginActionRequest{Action: action}
+			if action == api.PluginActionInstall {
+				request.Version, _ = command.Flags().GetString("version")
+			}
+
+			err := request.Validate()
+			if err != nil {
+				return errors.Wrapf(err, "invalid plugin %s", use)
+			}
+
+			result, err := client.RunWorkspacePluginAction(workspaceID, pluginID, request)
+			if err != nil {
+				return errors.Wrapf(err, "failed to %s plugin", use)
+			}
+
+			err = printJSON(result)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	command.Flags().String("id", "", "ID of the workspace of the plugin.")
+	command.Flags().String("plugin", "", "ID of the plugin.")
+	if action == api.PluginActionInstall {
+		command.Flags().String("version", "", "The marketplace version to install. Defaults to the latest version.")
+	}
+	command.MarkFlagRequired("id")
+	command.MarkFlagRequired("plugin")
+
+	return command
+}
+
+var workspaceHibernateCmd = &cobra.Command{
+	Use:   "hibernate",
+	Short: "Hibernate a stable workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		workspace, err := client.HibernateWorkspace(workspaceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to hibernate workspace")
+		}
+
+		err = printJSON(workspace)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceWakeCmd = &cobra.Command{
+	Use:   "wake",
+	Short: "Wake up a hibernating workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		workspace, err := client.WakeupWorkspace(workspaceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to wake up workspace")
+		}
+
+		err = printJSON(workspace)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var workspaceConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config of a workspace.",
+}
+
+var workspaceConfigSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change a single config setting of a workspace.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		client := newClient(command)
+
+		workspaceID, _ := command.Flags().GetString("id")
+		key, _ := command.Flags().GetString("key")
+		values, _ := command.Flags().GetStringArray("value")
+
+		request := &api.PatchWorkspaceConfigRequest{Key: key}
+		if len(values) == 1 {
+			request.Value = values[0]
+		} else {
+			list := make([]interface{}, len(values))
+			for i := range values {
+				list[i] = values[i]
+			}
+			request.Value = list
+		}
+
+		err := request.Validate()
+		if err != nil {
+			return errors.Wrap(err, "invalid config change")
+		}
+
+		change, err := client.PatchWorkspaceConfig(workspaceID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to change workspace config")
+		}
+
+		err = printJSON(change)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -3,15 +3,25 @@ module github.com/mattermost/pillar
 go 1.14
 
 require (
+	github.com/Masterminds/squirrel v1.4.0
+	github.com/alicebob/miniredis/v2 v2.14.1
+	github.com/coreos/go-oidc v2.2.1+incompatible
+	github.com/go-redis/redis/v8 v8.4.4
 	github.com/golang/mock v1.4.4
 	github.com/gorilla/mux v1.8.0
+	github.com/jmoiron/sqlx v1.2.0
+	github.com/lib/pq v1.8.0
 	github.com/mattermost/go-i18n v1.11.0
 	github.com/mattermost/mattermost-cloud v0.36.0
+	github.com/mattermost/mattermost-operator v1.11.1
 	github.com/mattermost/mattermost-server/v5 v5.30.1
+	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 	github.com/pborman/uuid v1.2.1
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.6.1
+	gopkg.in/square/go-jose.v2 v2.5.1
+	k8s.io/api v0.18.8
 )
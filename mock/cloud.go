@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/mattermost/mattermost-cloud/model"
 	reflect "reflect"
@@ -34,76 +35,180 @@ func (m *MockCloudClient) EXPECT() *MockCloudClientMockRecorder {
 }
 
 // GetInstallation mocks base method
-func (m *MockCloudClient) GetInstallation(arg0 string, arg1 *model.GetInstallationRequest) (*model.InstallationDTO, error) {
+func (m *MockCloudClient) GetInstallation(arg0 context.Context, arg1 string, arg2 *model.GetInstallationRequest) (*model.InstallationDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInstallation", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetInstallation", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*model.InstallationDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInstallation indicates an expected call of GetInstallation
-func (mr *MockCloudClientMockRecorder) GetInstallation(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockCloudClientMockRecorder) GetInstallation(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallation", reflect.TypeOf((*MockCloudClient)(nil).GetInstallation), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallation", reflect.TypeOf((*MockCloudClient)(nil).GetInstallation), arg0, arg1, arg2)
 }
 
 // GetInstallations mocks base method
-func (m *MockCloudClient) GetInstallations(arg0 *model.GetInstallationsRequest) ([]*model.InstallationDTO, error) {
+func (m *MockCloudClient) GetInstallations(arg0 context.Context, arg1 *model.GetInstallationsRequest) ([]*model.InstallationDTO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInstallations", arg0)
+	ret := m.ctrl.Call(m, "GetInstallations", arg0, arg1)
 	ret0, _ := ret[0].([]*model.InstallationDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInstallations indicates an expected call of GetInstallations
-func (mr *MockCloudClientMockRecorder) GetInstallations(arg0 interface{}) *gomock.Call {
+func (mr *MockCloudClientMockRecorder) GetInstallations(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallations", reflect.TypeOf((*MockCloudClient)(nil).GetInstallations), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallations", reflect.TypeOf((*MockCloudClient)(nil).GetInstallations), arg0, arg1)
+}
+
+// UpdateInstallation mocks base method
+func (m *MockCloudClient) UpdateInstallation(arg0 context.Context, arg1 string, arg2 *model.PatchInstallationRequest) (*model.InstallationDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstallation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.InstallationDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInstallation indicates an expected call of UpdateInstallation
+func (mr *MockCloudClientMockRecorder) UpdateInstallation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstallation", reflect.TypeOf((*MockCloudClient)(nil).UpdateInstallation), arg0, arg1, arg2)
+}
+
+// HibernateInstallation mocks base method
+func (m *MockCloudClient) HibernateInstallation(arg0 context.Context, arg1 string) (*model.InstallationDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HibernateInstallation", arg0, arg1)
+	ret0, _ := ret[0].(*model.InstallationDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HibernateInstallation indicates an expected call of HibernateInstallation
+func (mr *MockCloudClientMockRecorder) HibernateInstallation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HibernateInstallation", reflect.TypeOf((*MockCloudClient)(nil).HibernateInstallation), arg0, arg1)
+}
+
+// WakeupInstallation mocks base method
+func (m *MockCloudClient) WakeupInstallation(arg0 context.Context, arg1 string) (*model.InstallationDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WakeupInstallation", arg0, arg1)
+	ret0, _ := ret[0].(*model.InstallationDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WakeupInstallation indicates an expected call of WakeupInstallation
+func (mr *MockCloudClientMockRecorder) WakeupInstallation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WakeupInstallation", reflect.TypeOf((*MockCloudClient)(nil).WakeupInstallation), arg0, arg1)
+}
+
+// DeleteInstallation mocks base method
+func (m *MockCloudClient) DeleteInstallation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInstallation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInstallation indicates an expected call of DeleteInstallation
+func (mr *MockCloudClientMockRecorder) DeleteInstallation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInstallation", reflect.TypeOf((*MockCloudClient)(nil).DeleteInstallation), arg0, arg1)
+}
+
+// GetCluster mocks base method
+func (m *MockCloudClient) GetCluster(arg0 context.Context, arg1 string) (*model.ClusterDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCluster", arg0, arg1)
+	ret0, _ := ret[0].(*model.ClusterDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCluster indicates an expected call of GetCluster
+func (mr *MockCloudClientMockRecorder) GetCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockCloudClient)(nil).GetCluster), arg0, arg1)
+}
+
+// GetClusters mocks base method
+func (m *MockCloudClient) GetClusters(arg0 context.Context, arg1 *model.GetClustersRequest) ([]*model.ClusterDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusters", arg0, arg1)
+	ret0, _ := ret[0].([]*model.ClusterDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusters indicates an expected call of GetClusters
+func (mr *MockCloudClientMockRecorder) GetClusters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusters", reflect.TypeOf((*MockCloudClient)(nil).GetClusters), arg0, arg1)
 }
 
 // GetClusterInstallations mocks base method
-func (m *MockCloudClient) GetClusterInstallations(arg0 *model.GetClusterInstallationsRequest) ([]*model.ClusterInstallation, error) {
+func (m *MockCloudClient) GetClusterInstallations(arg0 context.Context, arg1 *model.GetClusterInstallationsRequest) ([]*model.ClusterInstallation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetClusterInstallations", arg0)
+	ret := m.ctrl.Call(m, "GetClusterInstallations", arg0, arg1)
 	ret0, _ := ret[0].([]*model.ClusterInstallation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetClusterInstallations indicates an expected call of GetClusterInstallations
-func (mr *MockCloudClientMockRecorder) GetClusterInstallations(arg0 interface{}) *gomock.Call {
+func (mr *MockCloudClientMockRecorder) GetClusterInstallations(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterInstallations", reflect.TypeOf((*MockCloudClient)(nil).GetClusterInstallations), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterInstallations", reflect.TypeOf((*MockCloudClient)(nil).GetClusterInstallations), arg0, arg1)
 }
 
 // ExecClusterInstallationCLI mocks base method
-func (m *MockCloudClient) ExecClusterInstallationCLI(arg0, arg1 string, arg2 []string) ([]byte, error) {
+func (m *MockCloudClient) ExecClusterInstallationCLI(arg0 context.Context, arg1, arg2 string, arg3 []string) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecClusterInstallationCLI", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ExecClusterInstallationCLI", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExecClusterInstallationCLI indicates an expected call of ExecClusterInstallationCLI
-func (mr *MockCloudClientMockRecorder) ExecClusterInstallationCLI(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockCloudClientMockRecorder) ExecClusterInstallationCLI(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecClusterInstallationCLI", reflect.TypeOf((*MockCloudClient)(nil).ExecClusterInstallationCLI), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecClusterInstallationCLI", reflect.TypeOf((*MockCloudClient)(nil).ExecClusterInstallationCLI), arg0, arg1, arg2, arg3)
 }
 
 // GetGroup mocks base method
-func (m *MockCloudClient) GetGroup(arg0 string) (*model.Group, error) {
+func (m *MockCloudClient) GetGroup(arg0 context.Context, arg1 string) (*model.Group, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetGroup", arg0)
+	ret := m.ctrl.Call(m, "GetGroup", arg0, arg1)
 	ret0, _ := ret[0].(*model.Group)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetGroup indicates an expected call of GetGroup
-func (mr *MockCloudClientMockRecorder) GetGroup(arg0 interface{}) *gomock.Call {
+func (mr *MockCloudClientMockRecorder) GetGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockCloudClient)(nil).GetGroup), arg0, arg1)
+}
+
+// GetGroups mocks base method
+func (m *MockCloudClient) GetGroups(arg0 context.Context, arg1 *model.GetGroupsRequest) ([]*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroups", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroups indicates an expected call of GetGroups
+func (mr *MockCloudClientMockRecorder) GetGroups(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockCloudClient)(nil).GetGroup), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroups", reflect.TypeOf((*MockCloudClient)(nil).GetGroups), arg0, arg1)
 }
@@ -0,0 +1,73 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/pillar/api"
+)
+
+var auditRecordSelect sq.SelectBuilder
+
+func init() {
+	auditRecordSelect = sq.
+		Select("RequestID", "CreateAt", "Actor", "Method", "Path", "WorkspaceID",
+			"Permission", "IPAddress", "StatusCode", "Justification", "TicketID").
+		From("AuditRecord")
+}
+
+// CreateAuditRecord records the given audit record to the database, setting its creation time.
+func (sqlStore *SQLStore) CreateAuditRecord(record *api.AuditRecord) error {
+	record.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("AuditRecord").
+		SetMap(map[string]interface{}{
+			"RequestID":     record.RequestID,
+			"CreateAt":      record.CreateAt,
+			"Actor":         record.Actor,
+			"Method":        record.Method,
+			"Path":          record.Path,
+			"WorkspaceID":   record.WorkspaceID,
+			"Permission":    record.Permission,
+			"IPAddress":     record.IPAddress,
+			"StatusCode":    record.StatusCode,
+			"Justification": record.Justification,
+			"TicketID":      record.TicketID,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create audit record")
+	}
+
+	return nil
+}
+
+// GetAuditRecords fetches the given page of audit records, newest first. The first page is 0.
+func (sqlStore *SQLStore) GetAuditRecords(filter *api.AuditFilter) ([]*api.AuditRecord, error) {
+	builder := auditRecordSelect.
+		OrderBy("CreateAt DESC", "RequestID DESC").
+		Limit(uint64(filter.PerPage)).
+		Offset(uint64(filter.Page * filter.PerPage))
+
+	if filter.Actor != "" {
+		builder = builder.Where("Actor = ?", filter.Actor)
+	}
+	if filter.WorkspaceID != "" {
+		builder = builder.Where("WorkspaceID = ?", filter.WorkspaceID)
+	}
+	if filter.Since > 0 {
+		builder = builder.Where("CreateAt >= ?", filter.Since)
+	}
+	if filter.Until > 0 {
+		builder = builder.Where("CreateAt < ?", filter.Until)
+	}
+
+	records := []*api.AuditRecord{}
+	err := sqlStore.selectBuilder(sqlStore.db, &records, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for audit records")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/pillar/api"
+	"github.com/mattermost/pillar/testlib"
+	"github.com/mattermost/pillar/utils"
+)
+
+func TestAuditRecords(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer sqlStore.Close()
+
+	record1 := &api.AuditRecord{
+		RequestID:   utils.NewID(),
+		Actor:       "alice@mattermost.com",
+		Method:      "GET",
+		Path:        "/api/v1/workspaces/workspace1",
+		WorkspaceID: "workspace1",
+		Permission:  string(api.PermissionWorkspaceRead),
+		IPAddress:   "10.0.0.1",
+		StatusCode:  200,
+	}
+	err := sqlStore.CreateAuditRecord(record1)
+	require.NoError(t, err)
+	assert.NotZero(t, record1.CreateAt)
+
+	time.Sleep(2 * time.Millisecond)
+
+	record2 := &api.AuditRecord{
+		RequestID:     utils.NewID(),
+		Actor:         "bob@mattermost.com",
+		Method:        "PUT",
+		Path:          "/api/v1/workspaces/workspace2",
+		WorkspaceID:   "workspace2",
+		Permission:    string(api.PermissionWorkspaceUpdate),
+		IPAddress:     "10.0.0.2",
+		StatusCode:    202,
+		Justification: "customer asked for an upgrade",
+		TicketID:      "12345",
+	}
+	err = sqlStore.CreateAuditRecord(record2)
+	require.NoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	record3 := &api.AuditRecord{
+		RequestID:   utils.NewID(),
+		Actor:       "alice@mattermost.com",
+		Method:      "GET",
+		Path:        "/api/v1/workspaces/workspace2",
+		WorkspaceID: "workspace2",
+		Permission:  string(api.PermissionWorkspaceRead),
+		StatusCode:  403,
+	}
+	err = sqlStore.CreateAuditRecord(record3)
+	require.NoError(t, err)
+
+	t.Run("duplicate request ID", func(t *testing.T) {
+		err := sqlStore.CreateAuditRecord(&api.AuditRecord{RequestID: record1.RequestID})
+		assert.Error(t, err)
+	})
+
+	testCases := []struct {
+		description string
+		filter      *api.AuditFilter
+		expected    []*api.AuditRecord
+	}{
+		{"all", &api.AuditFilter{PerPage: 100}, []*api.AuditRecord{record3, record2, record1}},
+		{"paged", &api.AuditFilter{Page: 1, PerPage: 2}, []*api.AuditRecord{record1}},
+		{"by actor", &api.AuditFilter{Actor: "alice@mattermost.com", PerPage: 100}, []*api.AuditRecord{record3, record1}},
+		{"by workspace", &api.AuditFilter{WorkspaceID: "workspace2", PerPage: 100}, []*api.AuditRecord{record3, record2}},
+		{"by actor and workspace", &api.AuditFilter{Actor: "bob@mattermost.com", WorkspaceID: "workspace2", PerPage: 100}, []*api.AuditRecord{record2}},
+		{"since", &api.AuditFilter{Since: record2.CreateAt, PerPage: 100}, []*api.AuditRecord{record3, record2}},
+		{"until", &api.AuditFilter{Until: record2.CreateAt, PerPage: 100}, []*api.AuditRecord{record1}},
+		{"since and until", &api.AuditFilter{Since: record2.CreateAt, Until: record3.CreateAt, PerPage: 100}, []*api.AuditRecord{record2}},
+		{"no match", &api.AuditFilter{Actor: "carol@mattermost.com", PerPage: 100}, []*api.AuditRecord{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			records, err := sqlStore.GetAuditRecords(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer sqlStore.Close()
+
+	version, err := sqlStore.getCurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, LatestVersion(), version)
+
+	// Migrating again is a no-op.
+	err = sqlStore.Migrate()
+	require.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"database/sql"
+	"strconv"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+)
+
+const systemSchemaVersionKey = "SchemaVersion"
+
+// LatestVersion returns the version to which the last migration migrates.
+func LatestVersion() int {
+	return len(migrations)
+}
+
+// Migrate advances the schema of the configured database to the latest version.
+func (sqlStore *SQLStore) Migrate() error {
+	currentVersion, err := sqlStore.getCurrentVersion()
+	if err != nil {
+		return err
+	}
+
+	sqlStore.logger.Infof("Schema version is %d, latest version is %d", currentVersion, LatestVersion())
+
+	applied := 0
+	for version := currentVersion; version < LatestVersion(); version++ {
+		err := func() error {
+			sqlStore.logger.Infof("Migrating schema from %d to %d", version, version+1)
+			tx, err := sqlStore.db.Beginx()
+			if err != nil {
+				return errors.Wrapf(err, "failed to begin applying target version %d", version+1)
+			}
+			defer tx.Rollback()
+
+			err = migrations[version](tx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to migrate to target version %d", version+1)
+			}
+
+			err = sqlStore.setCurrentVersion(tx, version+1)
+			if err != nil {
+				return errors.Wrap(err, "failed to record target version")
+			}
+
+			err = tx.Commit()
+			if err != nil {
+				return errors.Wrapf(err, "failed to commit target version %d", version+1)
+			}
+
+			applied++
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	sqlStore.logger.Infof("Applied %d migration(s)", applied)
+
+	return nil
+}
+
+func (sqlStore *SQLStore) getCurrentVersion() (int, error) {
+	var count int
+	var err error
+	switch sqlStore.db.DriverName() {
+	case driverSqlite:
+		err = sqlStore.db.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'System'")
+	case driverPostgres:
+		err = sqlStore.db.Get(&count, "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'system'")
+	default:
+		return 0, errors.Errorf("unsupported driver %s", sqlStore.db.DriverName())
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to check if system table exists")
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	var value string
+	err = sqlStore.getBuilder(sqlStore.db, &value,
+		sq.Select("Value").From("System").Where("Key = ?", systemSchemaVersionKey),
+	)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "failed to query schema version")
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse schema version %s", value)
+	}
+
+	return version, nil
+}
+
+func (sqlStore *SQLStore) setCurrentVersion(e execer, version int) error {
+	result, err := sqlStore.execBuilder(e,
+		sq.Update("System").Set("Value", strconv.Itoa(version)).Where("Key = ?", systemSchemaVersionKey),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update schema version")
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	_, err = sqlStore.execBuilder(e,
+		sq.Insert("System").Columns("Key", "Value").Values(systemSchemaVersionKey, strconv.Itoa(version)),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert schema version")
+	}
+
+	return nil
+}
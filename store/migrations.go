@@ -0,0 +1,152 @@
+package store
+
+const (
+	driverPostgres = "postgres"
+	driverSqlite   = "sqlite3"
+)
+
+// migrations defines the set of migrations necessary to advance the database
+// to the latest expected version. The migration at index i advances the schema
+// from version i to version i+1.
+//
+// Note that the canonical schema is currently obtained by applying all migrations to an empty
+// database.
+var migrations = []func(execer) error{
+	func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE System (
+				Key VARCHAR(64) PRIMARY KEY,
+				Value VARCHAR(1024) NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`
+			CREATE TABLE AuditRecord (
+				RequestID CHAR(26) PRIMARY KEY,
+				CreateAt BIGINT NOT NULL,
+				Actor VARCHAR(256) NOT NULL,
+				Method VARCHAR(16) NOT NULL,
+				Path TEXT NOT NULL,
+				WorkspaceID VARCHAR(64) NOT NULL,
+				Permission VARCHAR(64) NOT NULL,
+				IPAddress VARCHAR(64) NOT NULL,
+				StatusCode INTEGER NOT NULL,
+				Justification TEXT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX AuditRecord_CreateAt ON AuditRecord (CreateAt);`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX AuditRecord_Actor ON AuditRecord (Actor, CreateAt);`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX AuditRecord_WorkspaceID ON AuditRecord (WorkspaceID, CreateAt);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE AuditRecord ADD COLUMN TicketID VARCHAR(128) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	func(e execer) error {
+		_, err := e.Exec(`
+			CREATE TABLE Workspace (
+				ID VARCHAR(64) PRIMARY KEY,
+				GroupID VARCHAR(64) NOT NULL,
+				Version VARCHAR(128) NOT NULL,
+				DNS VARCHAR(512) NOT NULL,
+				Size VARCHAR(64) NOT NULL,
+				Database VARCHAR(64) NOT NULL,
+				Filestore VARCHAR(64) NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				DeleteAt BIGINT NOT NULL,
+				Edition VARCHAR(64) NOT NULL,
+				SyncedAt BIGINT NOT NULL
+			);
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX Workspace_DNS ON Workspace (DNS);`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX Workspace_SyncedAt ON Workspace (SyncedAt);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE Workspace ADD COLUMN State VARCHAR(64) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN Image VARCHAR(512) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN Affinity VARCHAR(64) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN HasLicense BOOLEAN NOT NULL DEFAULT FALSE;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`CREATE INDEX Workspace_State ON Workspace (State);`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	func(e execer) error {
+		_, err := e.Exec(`ALTER TABLE Workspace ADD COLUMN LicenseID VARCHAR(64) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN LicenseSKU VARCHAR(64) NOT NULL DEFAULT '';`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN LicenseSeats INTEGER NOT NULL DEFAULT 0;`)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.Exec(`ALTER TABLE Workspace ADD COLUMN LicenseExpiresAt BIGINT NOT NULL DEFAULT 0;`)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/pillar/utils"
+)
+
+// MakeTestSQLStore creates a migrated SQLStore for use with unit tests.
+//
+// An in-memory sqlite database is used unless PILLAR_DATABASE is set.
+func MakeTestSQLStore(tb testing.TB, logger log.FieldLogger) *SQLStore {
+	dsn := os.Getenv("PILLAR_DATABASE")
+	if dsn == "" {
+		dsn = fmt.Sprintf("sqlite3://file:%s.db?mode=memory&cache=shared", utils.NewID())
+	} else {
+		dsnURL, err := url.Parse(dsn)
+		require.NoError(tb, err)
+
+		switch dsnURL.Scheme {
+		case "postgres", "postgresql":
+			q := dsnURL.Query()
+			q.Add("pg_temp", "true")
+			dsnURL.RawQuery = q.Encode()
+			dsn = dsnURL.String()
+		}
+	}
+
+	sqlStore, err := New(dsn, logger)
+	require.NoError(tb, err)
+
+	// For testing with mode=memory and pg_temp above, restrict to a single connection,
+	// otherwise multiple goroutines may not see consistent views / have consistent access.
+	sqlStore.db.SetMaxOpenConns(1)
+
+	err = sqlStore.Migrate()
+	require.NoError(tb, err)
+
+	return sqlStore
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/pillar/api"
+)
+
+var workspaceSelect sq.SelectBuilder
+
+func init() {
+	workspaceSelect = sq.
+		Select("ID", "GroupID", "State", "Version", "Image", "DNS", "Size", "Affinity",
+			"Database", "Filestore", "HasLicense", "LicenseID", "LicenseSKU", "LicenseSeats",
+			"LicenseExpiresAt", "CreateAt", "DeleteAt", "Edition").
+		From("Workspace")
+}
+
+// workspaceSortColumns maps the sort fields of a workspace search to columns.
+var workspaceSortColumns = map[string]string{
+	api.WorkspaceSortDNS:      "DNS",
+	api.WorkspaceSortVersion:  "Version",
+	api.WorkspaceSortSize:     "Size",
+	api.WorkspaceSortCreateAt: "CreateAt",
+}
+
+// UpsertWorkspaces adds or updates the given workspaces in the index, marking
+// them as synced at the given time.
+func (sqlStore *SQLStore) UpsertWorkspaces(workspaces []*api.Workspace, syncedAt int64) error {
+	if len(workspaces) == 0 {
+		return nil
+	}
+
+	tx, err := sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, workspace := range workspaces {
+		_, err = sqlStore.execBuilder(tx, sq.
+			Insert("Workspace").
+			SetMap(map[string]interface{}{
+				"ID":               workspace.ID,
+				"GroupID":          workspace.GroupID,
+				"State":            workspace.State,
+				"Version":          workspace.Version,
+				"Image":            workspace.Image,
+				"DNS":              workspace.DNS,
+				"Size":             workspace.Size,
+				"Affinity":         workspace.Affinity,
+				"Database":         workspace.Database,
+				"Filestore":        workspace.Filestore,
+				"HasLicense":       workspace.HasLicense,
+				"LicenseID":        workspace.LicenseID,
+				"LicenseSKU":       workspace.LicenseSKU,
+				"LicenseSeats":     workspace.LicenseSeats,
+				"LicenseExpiresAt": workspace.LicenseExpiresAt,
+				"CreateAt":         workspace.CreateAt,
+				"DeleteAt":         workspace.DeleteAt,
+				"Edition":          workspace.Edition,
+				"SyncedAt":         syncedAt,
+			}).
+			Suffix(`ON CONFLICT (ID) DO UPDATE SET
+				GroupID = excluded.GroupID,
+				State = excluded.State,
+				Version = excluded.Version,
+				Image = excluded.Image,
+				DNS = excluded.DNS,
+				Size = excluded.Size,
+				Affinity = excluded.Affinity,
+				Database = excluded.Database,
+				Filestore = excluded.Filestore,
+				HasLicense = excluded.HasLicense,
+				LicenseID = excluded.LicenseID,
+				LicenseSKU = excluded.LicenseSKU,
+				LicenseSeats = excluded.LicenseSeats,
+				LicenseExpiresAt = excluded.LicenseExpiresAt,
+				CreateAt = excluded.CreateAt,
+				DeleteAt = excluded.DeleteAt,
+				Edition = excluded.Edition,
+				SyncedAt = excluded.SyncedAt`),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upsert workspace %s", workspace.ID)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// DeleteWorkspacesSyncedBefore removes the workspaces last synced before the given time from the index.
+func (sqlStore *SQLStore) DeleteWorkspacesSyncedBefore(syncedAt int64) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("Workspace").
+		Where("SyncedAt < ?", syncedAt),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete workspaces")
+	}
+
+	return nil
+}
+
+// SearchWorkspaces fetches the given page of workspaces matching the search.
+//
+// The query matches workspaces by their exact ID, or whose DNS contains every
+// word of the query regardless of case and dashes, so both "Acme Corp" and
+// "acmecorp" match acme-corp.cloud.mattermost.com.
+func (sqlStore *SQLStore) SearchWorkspaces(search *api.WorkspaceSearch) ([]*api.Workspace, error) {
+	sortColumn := workspaceSortColumns[search.Sort]
+	if sortColumn == "" {
+		sortColumn = "DNS"
+	}
+	direction := "ASC"
+	if search.Descending {
+		direction = "DESC"
+	}
+
+	builder := workspaceSelect.
+		OrderBy(sortColumn+" "+direction, "ID "+direction).
+		Limit(uint64(search.PerPage)).
+		Offset(uint64(search.Page * search.PerPage))
+
+	if query := strings.TrimSpace(search.Query); query != "" {
+		match := sq.Or{sq.Eq{"ID": query}}
+		if words := searchWords(query); len(words) > 0 {
+			dnsMatch := sq.And{}
+			for _, word := range words {
+				pattern := "%" + word + "%"
+				dnsMatch = append(dnsMatch, sq.Expr("(LOWER(DNS) LIKE ? OR REPLACE(LOWER(DNS), '-', '') LIKE ?)", pattern, pattern))
+			}
+			match = append(match, dnsMatch)
+		}
+		builder = builder.Where(match)
+	}
+	if search.State != "" {
+		builder = builder.Where("State = ?", search.State)
+	}
+	if search.Version != "" {
+		builder = builder.Where("Version = ?", search.Version)
+	}
+	if search.Size != "" {
+		builder = builder.Where("Size = ?", search.Size)
+	}
+	if search.Edition != "" {
+		builder = builder.Where("Edition = ?", search.Edition)
+	}
+	if search.Database != "" {
+		builder = builder.Where("Database = ?", search.Database)
+	}
+	if search.Filestore != "" {
+		builder = builder.Where("Filestore = ?", search.Filestore)
+	}
+	if !search.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
+
+	workspaces := []*api.Workspace{}
+	err := sqlStore.selectBuilder(sqlStore.db, &workspaces, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for workspaces")
+	}
+
+	return workspaces, nil
+}
+
+// searchWords splits a search query into lowercase words of letters and
+// digits, dropping punctuation such as the dots and dashes of a DNS.
+func searchWords(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
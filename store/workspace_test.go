@@ -0,0 +1,114 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/pillar/api"
+	"github.com/mattermost/pillar/testlib"
+)
+
+func TestWorkspaceIndex(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer sqlStore.Close()
+
+	acme := &api.Workspace{
+		ID:               "workspace1",
+		State:            "stable",
+		Image:            "mattermost/mattermost-enterprise-edition",
+		Affinity:         "multitenant",
+		HasLicense:       true,
+		LicenseID:        "licenseid",
+		LicenseSKU:       "Cloud Professional",
+		LicenseSeats:     10,
+		LicenseExpiresAt: 1700000000000,
+		DNS:              "acme-corp.cloud.mattermost.com",
+		Version:          "5.31.0",
+		Size:             "cloud10users",
+		Database:         "aws-multitenant-rds-postgres",
+		Filestore:        "bifrost",
+		Edition:          api.WorkspaceEditionProfessional,
+		CreateAt:         10,
+	}
+	acmeLabs := &api.Workspace{
+		ID:        "workspace2",
+		State:     "hibernating",
+		DNS:       "acmelabs.cloud.mattermost.com",
+		Version:   "5.32.0",
+		Size:      "cloud100users",
+		Database:  "aws-multitenant-rds-postgres",
+		Filestore: "bifrost",
+		Edition:   api.WorkspaceEditionEnterprise,
+		CreateAt:  20,
+	}
+	globex := &api.Workspace{
+		ID:        "workspace3",
+		State:     "deleted",
+		DNS:       "globex.cloud.mattermost.com",
+		Version:   "5.31.0",
+		Size:      "cloud10users",
+		Database:  "aws-rds",
+		Filestore: "aws-s3",
+		Edition:   api.WorkspaceEditionProfessional,
+		CreateAt:  30,
+		DeleteAt:  40,
+	}
+
+	err := sqlStore.UpsertWorkspaces([]*api.Workspace{acme, acmeLabs, globex}, 100)
+	require.NoError(t, err)
+
+	search := func(search *api.WorkspaceSearch) []*api.Workspace {
+		if search.PerPage == 0 {
+			search.PerPage = 100
+		}
+		workspaces, err := sqlStore.SearchWorkspaces(search)
+		require.NoError(t, err)
+		return workspaces
+	}
+
+	t.Run("all", func(t *testing.T) {
+		assert.Equal(t, []*api.Workspace{acme, acmeLabs}, search(&api.WorkspaceSearch{}))
+		assert.Equal(t, []*api.Workspace{acme, acmeLabs, globex}, search(&api.WorkspaceSearch{IncludeDeleted: true}))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		assert.Equal(t, []*api.Workspace{acme, acmeLabs}, search(&api.WorkspaceSearch{Query: "ACME"}))
+		assert.Equal(t, []*api.Workspace{acme}, search(&api.WorkspaceSearch{Query: "Acme Corp"}))
+		assert.Equal(t, []*api.Workspace{acme}, search(&api.WorkspaceSearch{Query: "acmecorp"}))
+		assert.Equal(t, []*api.Workspace{acmeLabs}, search(&api.WorkspaceSearch{Query: "workspace2"}))
+		assert.Equal(t, []*api.Workspace{globex}, search(&api.WorkspaceSearch{Query: "glob", IncludeDeleted: true}))
+		assert.Empty(t, search(&api.WorkspaceSearch{Query: "initech"}))
+		assert.Empty(t, search(&api.WorkspaceSearch{Query: "%"}), "wildcards are not words")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		assert.Equal(t, []*api.Workspace{acme, globex}, search(&api.WorkspaceSearch{Version: "5.31.0", IncludeDeleted: true}))
+		assert.Equal(t, []*api.Workspace{acmeLabs}, search(&api.WorkspaceSearch{Size: "cloud100users"}))
+		assert.Equal(t, []*api.Workspace{acmeLabs}, search(&api.WorkspaceSearch{Edition: api.WorkspaceEditionEnterprise}))
+		assert.Equal(t, []*api.Workspace{globex}, search(&api.WorkspaceSearch{Database: "aws-rds", IncludeDeleted: true}))
+		assert.Equal(t, []*api.Workspace{acme, acmeLabs}, search(&api.WorkspaceSearch{Filestore: "bifrost"}))
+		assert.Equal(t, []*api.Workspace{acme}, search(&api.WorkspaceSearch{Query: "acme", Version: "5.31.0"}))
+		assert.Equal(t, []*api.Workspace{acmeLabs}, search(&api.WorkspaceSearch{State: "hibernating"}))
+	})
+
+	t.Run("sort and paging", func(t *testing.T) {
+		assert.Equal(t, []*api.Workspace{globex, acmeLabs, acme}, search(&api.WorkspaceSearch{Sort: api.WorkspaceSortCreateAt, Descending: true, IncludeDeleted: true}))
+		assert.Equal(t, []*api.Workspace{acmeLabs, acme}, search(&api.WorkspaceSearch{Sort: api.WorkspaceSortVersion, Descending: true}))
+		assert.Equal(t, []*api.Workspace{acmeLabs}, search(&api.WorkspaceSearch{Sort: api.WorkspaceSortDNS, Page: 1, PerPage: 1}))
+	})
+
+	t.Run("update and remove stale workspaces", func(t *testing.T) {
+		updatedAcme := *acme
+		updatedAcme.Version = "5.33.0"
+
+		err := sqlStore.UpsertWorkspaces([]*api.Workspace{&updatedAcme, acmeLabs}, 200)
+		require.NoError(t, err)
+		err = sqlStore.DeleteWorkspacesSyncedBefore(200)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*api.Workspace{&updatedAcme, acmeLabs}, search(&api.WorkspaceSearch{IncludeDeleted: true}))
+	})
+}
@@ -0,0 +1,90 @@
+package testlib
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// OIDCIssuer is a local stand-in for an OIDC provider, serving discovery and
+// signing keys and issuing tokens for use in tests.
+type OIDCIssuer struct {
+	*httptest.Server
+	tb     testing.TB
+	signer jose.Signer
+}
+
+// NewOIDCIssuer starts a new OIDC issuer. Close must be called when done.
+func NewOIDCIssuer(tb testing.TB) *OIDCIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(tb, err)
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"),
+	)
+	require.NoError(tb, err)
+
+	issuer := &OIDCIssuer{tb: tb, signer: signer}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/auth",
+			"token_endpoint":                        issuer.URL + "/token",
+			"jwks_uri":                              issuer.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"}},
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+// Token issues a signed token for the given subject and audience, merging in any extra claims.
+func (i *OIDCIssuer) Token(subject, audience string, extraClaims map[string]interface{}) string {
+	now := time.Now()
+	claims := jwt.Claims{
+		Issuer:   i.URL,
+		Subject:  subject,
+		Audience: jwt.Audience{audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+
+	token, err := jwt.Signed(i.signer).Claims(claims).Claims(extraClaims).CompactSerialize()
+	require.NoError(i.tb, err)
+
+	return token
+}
+
+// ExpiredToken issues a signed token for the given subject and audience that has already expired.
+func (i *OIDCIssuer) ExpiredToken(subject, audience string) string {
+	past := time.Now().Add(-2 * time.Hour)
+	claims := jwt.Claims{
+		Issuer:   i.URL,
+		Subject:  subject,
+		Audience: jwt.Audience{audience},
+		IssuedAt: jwt.NewNumericDate(past),
+		Expiry:   jwt.NewNumericDate(past.Add(time.Hour)),
+	}
+
+	token, err := jwt.Signed(i.signer).Claims(claims).CompactSerialize()
+	require.NoError(i.tb, err)
+
+	return token
+}
@@ -27,41 +27,75 @@ func IsSecure(r *http.Request) bool {
 	return GetProtocol(r) == "https"
 }
 
-// GetIPFromRequest gets the client's ip address in the following
-// order:
-//   1. X-Forwarded-For header
+// GetIPFromRequest gets the client's ip address. Forwarding headers are only
+// honoured when the request arrives from one of the trusted proxy CIDR blocks,
+// since any client can set them:
+//   1. The right-most X-Forwarded-For entry that isn't a trusted proxy
 //   2. X-Real-IP header
 //   3. From the RemoteAddr field from the request
-func GetIPFromRequest(r *http.Request) (net.IP, error) {
-	realIPHeader := r.Header.Get(XRealIPHeader)
-	forwardedForHeader := r.Header.Get(XForwardedHeader)
+func GetIPFromRequest(r *http.Request, trustedProxies []string) (net.IP, error) {
+	remoteIPAddr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIPAddr = host
+	}
+	ipAddress := net.ParseIP(remoteIPAddr)
+	if ipAddress == nil {
+		return nil, errors.Errorf("ip address %s in request.RemoteIPAddr isn't valid", remoteIPAddr)
+	}
 
+	trusted, err := IPAddressInCIDRBlock(ipAddress, trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	if !trusted {
+		return ipAddress, nil
+	}
+
+	forwardedForHeader := r.Header.Get(XForwardedHeader)
 	if forwardedForHeader != "" {
+		// Each proxy appends the address it received the request from, so walk
+		// back from the right until reaching an address we don't trust.
 		ips := strings.Split(forwardedForHeader, ",")
-		ipAddress := net.ParseIP(ips[0])
-		if ipAddress == nil {
-			return nil, errors.Errorf("ip address %s in X-Forwarded-For header isn't valid", ipAddress)
+		for i := len(ips) - 1; i >= 0; i-- {
+			forwardedIPAddr := strings.TrimSpace(ips[i])
+			ipAddress = net.ParseIP(forwardedIPAddr)
+			if ipAddress == nil {
+				return nil, errors.Errorf("ip address %s in X-Forwarded-For header isn't valid", forwardedIPAddr)
+			}
+
+			trusted, err = IPAddressInCIDRBlock(ipAddress, trustedProxies)
+			if err != nil {
+				return nil, err
+			}
+			if !trusted {
+				break
+			}
 		}
 
 		return ipAddress, nil
 	}
 
+	realIPHeader := r.Header.Get(XRealIPHeader)
 	if realIPHeader != "" {
-		ipAddress := net.ParseIP(realIPHeader)
+		ipAddress = net.ParseIP(strings.TrimSpace(realIPHeader))
 		if ipAddress == nil {
-			return nil, errors.Errorf("ip address %s in X-Real-IP header isn't valid", ipAddress)
+			return nil, errors.Errorf("ip address %s in X-Real-IP header isn't valid", realIPHeader)
 		}
-
-		return ipAddress, nil
 	}
 
-	remoteIPAddr := strings.Split(r.RemoteAddr, ":")
-	ipAddress := net.ParseIP(remoteIPAddr[0])
-	if ipAddress == nil {
-		return nil, errors.Errorf("ip address %s in request.RemoteIPAddr isn't valid", remoteIPAddr)
+	return ipAddress, nil
+}
+
+// ValidateCIDRBlocks returns an error if any of the passed CIDR blocks isn't valid.
+func ValidateCIDRBlocks(cidrBlocks []string) error {
+	for _, cidr := range cidrBlocks {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Errorf("cidr block %s isn't valid", cidr)
+		}
 	}
 
-	return ipAddress, nil
+	return nil
 }
 
 // IPAddressInCIDRBlock returns true if the passed ip address belongs to one of the